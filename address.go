@@ -0,0 +1,118 @@
+package cryptomepay
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// AddressError reports a wallet address that is malformed for its chain
+type AddressError struct {
+	Chain   string
+	Address string
+	Reason  string
+}
+
+func (e *AddressError) Error() string {
+	return fmt.Sprintf("cryptomepay: invalid %s address %q: %s", e.Chain, e.Address, e.Reason)
+}
+
+// ValidateWalletAddress checks that address is well formed for chain.
+//
+// EVM chains (BSC, ETH, POLYGON, ARBITRUM) require 0x followed by 40 hex
+// digits, and mixed-case addresses must carry a valid EIP-55 checksum.
+// TRC20 requires a base58check address with the 0x41 TRON prefix.
+func ValidateWalletAddress(chain, address string) error {
+	switch chain {
+	case ChainBSC, ChainETH, ChainPolygon, ChainArbitrum:
+		return validateEVMAddress(chain, address)
+	case ChainTRC20:
+		return validateTronAddress(chain, address)
+	default:
+		return &AddressError{Chain: chain, Address: address, Reason: "unsupported chain"}
+	}
+}
+
+func validateEVMAddress(chain, address string) error {
+	if len(address) != 42 || !strings.HasPrefix(address, "0x") {
+		return &AddressError{Chain: chain, Address: address, Reason: "must be 0x followed by 40 hex digits"}
+	}
+	digits := address[2:]
+	if _, err := hex.DecodeString(digits); err != nil {
+		return &AddressError{Chain: chain, Address: address, Reason: "must be 0x followed by 40 hex digits"}
+	}
+
+	// Single-case addresses carry no checksum
+	if digits == strings.ToLower(digits) || digits == strings.ToUpper(digits) {
+		return nil
+	}
+	if digits != eip55Checksum(digits) {
+		return &AddressError{Chain: chain, Address: address, Reason: "EIP-55 checksum mismatch"}
+	}
+	return nil
+}
+
+// eip55Checksum returns the EIP-55 mixed-case form of 40 hex digits
+func eip55Checksum(digits string) string {
+	lower := strings.ToLower(digits)
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(lower))
+	hash := hex.EncodeToString(h.Sum(nil))
+
+	out := []byte(lower)
+	for i, ch := range out {
+		if ch >= 'a' && ch <= 'f' && hash[i] >= '8' {
+			out[i] = ch - 'a' + 'A'
+		}
+	}
+	return string(out)
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+func validateTronAddress(chain, address string) error {
+	decoded, ok := base58Decode(address)
+	if !ok {
+		return &AddressError{Chain: chain, Address: address, Reason: "not valid base58"}
+	}
+	if len(decoded) != 25 || decoded[0] != 0x41 {
+		return &AddressError{Chain: chain, Address: address, Reason: "must be a 21-byte TRON address with 0x41 prefix"}
+	}
+
+	first := sha256.Sum256(decoded[:21])
+	second := sha256.Sum256(first[:])
+	if !bytes.Equal(second[:4], decoded[21:]) {
+		return &AddressError{Chain: chain, Address: address, Reason: "base58check checksum mismatch"}
+	}
+	return nil
+}
+
+// base58Decode decodes a Bitcoin-alphabet base58 string
+func base58Decode(s string) ([]byte, bool) {
+	if s == "" {
+		return nil, false
+	}
+
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for _, ch := range s {
+		idx := strings.IndexRune(base58Alphabet, ch)
+		if idx < 0 {
+			return nil, false
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(idx)))
+	}
+
+	// Leading '1's encode leading zero bytes
+	zeros := 0
+	for zeros < len(s) && s[zeros] == '1' {
+		zeros++
+	}
+	return append(make([]byte, zeros), n.Bytes()...), true
+}
@@ -0,0 +1,61 @@
+package cryptomepay
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateWalletAddress(t *testing.T) {
+	valid := []struct{ chain, address string }{
+		{ChainETH, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+		{ChainBSC, "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"},
+		{ChainPolygon, "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359"},
+		{ChainTRC20, "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"},
+	}
+	for _, tc := range valid {
+		assert.NoError(t, ValidateWalletAddress(tc.chain, tc.address), tc.address)
+	}
+
+	invalid := []struct{ chain, address, reason string }{
+		{ChainETH, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", "EIP-55 checksum mismatch"},
+		{ChainArbitrum, "0x123", "must be 0x followed by 40 hex digits"},
+		{ChainBSC, "0xZZaeb6053f3e94c9b9a09f33669435e7ef1beaed", "must be 0x followed by 40 hex digits"},
+		{ChainTRC20, "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6u", "base58check checksum mismatch"},
+		{ChainTRC20, "0OIl", "not valid base58"},
+		{"DOGE", "D123", "unsupported chain"},
+	}
+	for _, tc := range invalid {
+		err := ValidateWalletAddress(tc.chain, tc.address)
+		var addrErr *AddressError
+		if assert.ErrorAs(t, err, &addrErr, tc.address) {
+			assert.Equal(t, tc.reason, addrErr.Reason, tc.address)
+			assert.Equal(t, tc.chain, addrErr.Chain)
+		}
+	}
+}
+
+func TestCreatePaymentResponseValidation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(PaymentResponse{
+			StatusCode: 200,
+			Data:       &PaymentData{TradeID: "CP1", ChainType: ChainBSC, Token: "0xcorrupted"},
+		})
+	}))
+	defer server.Close()
+
+	params := &CreatePaymentParams{OrderID: "ORDER_001", Amount: 100, NotifyURL: "https://example.com/webhook"}
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+	_, err := client.CreatePayment(params)
+	assert.NoError(t, err)
+
+	strict := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL), WithResponseValidation())
+	payment, err := strict.CreatePayment(params)
+	var addrErr *AddressError
+	assert.ErrorAs(t, err, &addrErr)
+	assert.Equal(t, "CP1", payment.Data.TradeID)
+}
@@ -0,0 +1,128 @@
+package cryptomepay
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// flexFloat decodes an amount sent either as a JSON number or as a numeric
+// string such as "100.00". An empty string or null decodes as zero.
+type flexFloat float64
+
+func (f *flexFloat) UnmarshalJSON(b []byte) error {
+	s := strings.TrimSpace(string(b))
+	if s == "null" {
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		unquoted, err := strconv.Unquote(s)
+		if err != nil {
+			return fmt.Errorf("invalid amount %s: %w", s, err)
+		}
+		s = strings.TrimSpace(unquoted)
+		if s == "" {
+			*f = 0
+			return nil
+		}
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("invalid amount %s: %w", s, err)
+	}
+	*f = flexFloat(v)
+	return nil
+}
+
+// UnmarshalJSON accepts amount fields as numbers or numeric strings
+func (d *PaymentData) UnmarshalJSON(b []byte) error {
+	type alias PaymentData
+	aux := struct {
+		*alias
+		Amount       flexFloat `json:"amount"`
+		ActualAmount flexFloat `json:"actual_amount"`
+		ExchangeRate flexFloat `json:"exchange_rate"`
+	}{alias: (*alias)(d)}
+
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	d.Amount = float64(aux.Amount)
+	d.ActualAmount = float64(aux.ActualAmount)
+	d.ExchangeRate = float64(aux.ExchangeRate)
+	return nil
+}
+
+// UnmarshalJSON accepts amount fields as numbers or numeric strings
+func (d *OrderData) UnmarshalJSON(b []byte) error {
+	type alias OrderData
+	aux := struct {
+		*alias
+		Amount         flexFloat `json:"amount"`
+		ActualAmount   flexFloat `json:"actual_amount"`
+		ExchangeRate   flexFloat `json:"exchange_rate"`
+		AmountReceived flexFloat `json:"amount_received"`
+	}{alias: (*alias)(d)}
+
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	d.Amount = float64(aux.Amount)
+	d.ActualAmount = float64(aux.ActualAmount)
+	d.ExchangeRate = float64(aux.ExchangeRate)
+	d.AmountReceived = float64(aux.AmountReceived)
+	return nil
+}
+
+// UnmarshalJSON accepts amount fields as numbers or numeric strings
+func (p *WebhookPayload) UnmarshalJSON(b []byte) error {
+	type alias WebhookPayload
+	aux := struct {
+		*alias
+		Amount         flexFloat `json:"amount"`
+		ActualAmount   flexFloat `json:"actual_amount"`
+		AmountReceived flexFloat `json:"amount_received"`
+	}{alias: (*alias)(p)}
+
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	p.Amount = float64(aux.Amount)
+	p.ActualAmount = float64(aux.ActualAmount)
+	p.AmountReceived = float64(aux.AmountReceived)
+	return nil
+}
+
+// normalizeAmount checks that s is a plain non-negative decimal with at
+// most decimals fractional digits and pads it to exactly that many, using
+// string operations only: "19.9" becomes "19.90" and "20" becomes "20.00".
+func normalizeAmount(s string, decimals int) (string, error) {
+	s = strings.TrimSpace(s)
+	whole, frac, hasPoint := strings.Cut(s, ".")
+	if whole == "" || !isDigits(whole) || (hasPoint && (frac == "" || !isDigits(frac))) {
+		return "", fmt.Errorf("%q is not a decimal amount", s)
+	}
+	if len(frac) > decimals {
+		return "", fmt.Errorf("%q has more than %d decimals", s, decimals)
+	}
+
+	whole = strings.TrimLeft(whole, "0")
+	if whole == "" {
+		whole = "0"
+	}
+	if decimals == 0 {
+		return whole, nil
+	}
+	return whole + "." + frac + strings.Repeat("0", decimals-len(frac)), nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
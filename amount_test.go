@@ -0,0 +1,71 @@
+package cryptomepay
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAmountsAsNumbersOrStrings(t *testing.T) {
+	bodies := []string{
+		`{"trade_id":"CP1","amount":100.00,"actual_amount":15.625,"status":2}`,
+		`{"trade_id":"CP1","amount":"100.00","actual_amount":"15.6250","status":2}`,
+	}
+
+	for _, body := range bodies {
+		var order OrderData
+		assert.NoError(t, json.Unmarshal([]byte(body), &order), body)
+		assert.Equal(t, "CP1", order.TradeID)
+		assert.Equal(t, 100.0, order.Amount)
+		assert.Equal(t, 15.625, order.ActualAmount)
+		assert.Equal(t, StatusPaid, order.Status)
+
+		var payment PaymentData
+		assert.NoError(t, json.Unmarshal([]byte(body), &payment), body)
+		assert.Equal(t, 100.0, payment.Amount)
+		assert.Equal(t, 15.625, payment.ActualAmount)
+
+		var payload WebhookPayload
+		assert.NoError(t, json.Unmarshal([]byte(body), &payload), body)
+		assert.Equal(t, 100.0, payload.Amount)
+		assert.Equal(t, 15.625, payload.ActualAmount)
+	}
+}
+
+func TestAmountsInvalidString(t *testing.T) {
+	var order OrderData
+	assert.Error(t, json.Unmarshal([]byte(`{"amount":"abc"}`), &order))
+
+	// Empty and null amounts decode as zero
+	assert.NoError(t, json.Unmarshal([]byte(`{"amount":"","actual_amount":null}`), &order))
+	assert.Equal(t, 0.0, order.Amount)
+}
+
+func TestOrderListAmountsAsStrings(t *testing.T) {
+	var list OrderListData
+	err := json.Unmarshal([]byte(`{"list":[{"trade_id":"CP1","actual_amount":"1.5000"}],"total":1}`), &list)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, list.List[0].ActualAmount)
+}
+
+func TestNormalizeAmount(t *testing.T) {
+	valid := map[string]string{
+		"19.99": "19.99",
+		"19.9":  "19.90",
+		"20":    "20.00",
+		"0.5":   "0.50",
+		"007.1": "7.10",
+		" 5 ":   "5.00",
+	}
+	for in, want := range valid {
+		got, err := normalizeAmount(in, 2)
+		assert.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	for _, in := range []string{"", "19.999", "-1", "1e2", "1.", ".5", "1,00", "abc"} {
+		_, err := normalizeAmount(in, 2)
+		assert.Error(t, err, in)
+	}
+}
@@ -0,0 +1,142 @@
+package cryptomepay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// tokenRefreshMargin is how long before expiry a bearer token is refreshed.
+// Tokens living less than twice as long are refreshed halfway through their
+// lifetime instead, so they are still reused.
+const tokenRefreshMargin = 60 * time.Second
+
+// ErrTokenAuthUnsupported is returned by Authenticate when the gateway does
+// not issue bearer tokens. The client keeps using the static api key.
+var ErrTokenAuthUnsupported = errors.New("cryptomepay: gateway does not issue bearer tokens")
+
+// TokenData holds an issued bearer token
+type TokenData struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// TokenResponse is the API response for token exchange
+type TokenResponse struct {
+	StatusCode int        `json:"status_code"`
+	Message    string     `json:"message"`
+	Data       *TokenData `json:"data"`
+	RequestID  string     `json:"request_id"`
+
+	ResponseMeta
+}
+
+// tokenState caches the bearer token obtained by Authenticate
+type tokenState struct {
+	mu        sync.Mutex
+	enabled   bool
+	token     string
+	expiresAt time.Time
+
+	// refreshAt is when the token is due for refresh, before expiresAt
+	refreshAt time.Time
+
+	// refreshMu serializes refreshes so concurrent calls share one exchange
+	refreshMu sync.Mutex
+}
+
+func (s *tokenState) get() (token string, refreshAt time.Time, enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.token, s.refreshAt, s.enabled
+}
+
+// Authenticate exchanges the api key and secret for a short-lived bearer
+// token. Once it succeeds, every request uses the token and refreshes it
+// shortly before it expires. If the gateway has no token endpoint,
+// ErrTokenAuthUnsupported is returned and the static api key stays in use.
+func (c *Client) Authenticate(ctx context.Context) (*TokenResponse, error) {
+	timestamp := c.timestamp()
+	nonce, err := c.nonce()
+	if err != nil {
+		return nil, err
+	}
+
+	creds := c.credentials()
+	params := map[string]string{
+		"api_key":   creds.apiKey,
+		"timestamp": timestamp,
+		"nonce":     nonce,
+	}
+	signature, err := c.generateSignature(creds, params)
+	if err != nil {
+		return nil, err
+	}
+
+	body := make(map[string]interface{}, len(params)+1)
+	for k, v := range params {
+		body[k] = v
+	}
+	body["signature"] = signature
+
+	var resp TokenResponse
+	err = c.request(ctx, "POST", "/auth/token", body, &resp, withStaticAuth(), withAPIKey(creds.apiKey))
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.HTTPStatus {
+		case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+			return &resp, ErrTokenAuthUnsupported
+		}
+	}
+	if err != nil {
+		return &resp, err
+	}
+	if resp.StatusCode != 200 || resp.Data == nil || resp.Data.AccessToken == "" {
+		return &resp, NewAPIError(resp.StatusCode, resp.Message, resp.RequestID)
+	}
+
+	lifetime := time.Duration(resp.Data.ExpiresIn) * time.Second
+	margin := tokenRefreshMargin
+	if lifetime/2 < margin {
+		margin = lifetime / 2
+	}
+
+	c.auth.mu.Lock()
+	c.auth.enabled = true
+	c.auth.token = resp.Data.AccessToken
+	c.auth.expiresAt = time.Now().Add(lifetime)
+	c.auth.refreshAt = c.auth.expiresAt.Add(-margin)
+	c.auth.mu.Unlock()
+
+	return &resp, nil
+}
+
+// bearerToken returns the credential for the Authorization header: the
+// cached token after Authenticate, refreshed when close to expiry, or
+// apiKey otherwise.
+func (c *Client) bearerToken(ctx context.Context, apiKey string) (string, error) {
+	token, refreshAt, enabled := c.auth.get()
+	if !enabled {
+		return apiKey, nil
+	}
+	if time.Now().Before(refreshAt) {
+		return token, nil
+	}
+
+	c.auth.refreshMu.Lock()
+	defer c.auth.refreshMu.Unlock()
+
+	// Another caller may have refreshed while we waited
+	if token, refreshAt, _ = c.auth.get(); time.Now().Before(refreshAt) {
+		return token, nil
+	}
+	if _, err := c.Authenticate(ctx); err != nil {
+		return "", fmt.Errorf("failed to refresh bearer token: %w", err)
+	}
+	token, _, _ = c.auth.get()
+	return token, nil
+}
@@ -0,0 +1,131 @@
+package cryptomepay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTokenServer(t *testing.T, expiresIn int64, tokenCalls *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/token":
+			*tokenCalls++
+			assert.Equal(t, "Bearer sk_test_key", r.Header.Get("Authorization"))
+
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			assert.NotEmpty(t, body["signature"])
+
+			json.NewEncoder(w).Encode(TokenResponse{
+				StatusCode: 200,
+				Data:       &TokenData{AccessToken: fmt.Sprintf("tok_%d", *tokenCalls), ExpiresIn: expiresIn},
+			})
+		default:
+			json.NewEncoder(w).Encode(MerchantResponse{
+				StatusCode: 200,
+				Data:       &MerchantData{MerchantCode: r.Header.Get("Authorization")},
+			})
+		}
+	}))
+}
+
+func TestAuthenticateUsesToken(t *testing.T) {
+	tokenCalls := 0
+	server := newTokenServer(t, 3600, &tokenCalls)
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	// Static key before authenticating
+	resp, err := client.GetMerchantInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer sk_test_key", resp.Data.MerchantCode)
+
+	_, err = client.Authenticate(context.Background())
+	assert.NoError(t, err)
+
+	resp, err = client.GetMerchantInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer tok_1", resp.Data.MerchantCode)
+
+	resp, err = client.GetMerchantInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer tok_1", resp.Data.MerchantCode)
+	assert.Equal(t, 1, tokenCalls)
+
+	// Clones do not inherit the token
+	clone := client.Clone()
+	resp, err = clone.GetMerchantInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer sk_test_key", resp.Data.MerchantCode)
+}
+
+func TestAuthenticateRefreshesExpiringToken(t *testing.T) {
+	tokenCalls := 0
+	server := newTokenServer(t, 3600, &tokenCalls)
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	_, err := client.Authenticate(context.Background())
+	assert.NoError(t, err)
+	assert.WithinDuration(t, client.auth.expiresAt.Add(-tokenRefreshMargin), client.auth.refreshAt, 0)
+
+	// Token expires inside the refresh margin, so it is renewed
+	client.auth.refreshAt = time.Now()
+	resp, err := client.GetMerchantInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer tok_2", resp.Data.MerchantCode)
+	assert.Equal(t, 2, tokenCalls)
+}
+
+func TestAuthenticateShortLivedToken(t *testing.T) {
+	tokenCalls := 0
+	server := newTokenServer(t, 30, &tokenCalls)
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	_, err := client.Authenticate(context.Background())
+	assert.NoError(t, err)
+
+	// A token shorter than the refresh margin is reused for half its life
+	// rather than exchanged again on every request
+	assert.WithinDuration(t, client.auth.expiresAt.Add(-15*time.Second), client.auth.refreshAt, 0)
+	for i := 0; i < 3; i++ {
+		resp, err := client.GetMerchantInfo()
+		assert.NoError(t, err)
+		assert.Equal(t, "Bearer tok_1", resp.Data.MerchantCode)
+	}
+	assert.Equal(t, 1, tokenCalls)
+}
+
+func TestAuthenticateUnsupported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/auth/token" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(MerchantResponse{
+			StatusCode: 200,
+			Data:       &MerchantData{MerchantCode: r.Header.Get("Authorization")},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	_, err := client.Authenticate(context.Background())
+	assert.ErrorIs(t, err, ErrTokenAuthUnsupported)
+
+	resp, err := client.GetMerchantInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer sk_test_key", resp.Data.MerchantCode)
+}
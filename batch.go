@@ -0,0 +1,226 @@
+package cryptomepay
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultBatchConcurrency is the default number of CreatePaymentBatch
+// requests in flight at once
+const DefaultBatchConcurrency = 8
+
+// WithBatchConcurrency sets how many requests CreatePaymentBatch,
+// QueryPaymentsByTradeIDs and the BulkCreatePayments fallback keep in
+// flight at once. Values <= 0 are ignored.
+func WithBatchConcurrency(n int) Option {
+	return func(c *Client) {
+		if n > 0 {
+			c.batchConcurrency = n
+		}
+	}
+}
+
+// BatchItemError is the failure of one order in CreatePaymentBatch
+type BatchItemError struct {
+	// Index is the position of the order in the input slice
+	Index   int
+	OrderID string
+	Err     error
+}
+
+func (e *BatchItemError) Error() string {
+	return fmt.Sprintf("order %d (%s): %v", e.Index, e.OrderID, e.Err)
+}
+
+func (e *BatchItemError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError is returned by CreatePaymentBatch when any order failed. Items
+// are in input order; errors.Is and errors.As see every item's error.
+type BatchError struct {
+	Total int
+	Items []*BatchItemError
+}
+
+func (e *BatchError) Error() string {
+	msg := fmt.Sprintf("cryptomepay: %d of %d orders failed", len(e.Items), e.Total)
+	for _, item := range e.Items {
+		msg += "; " + item.Error()
+	}
+	return msg
+}
+
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Items))
+	for i, item := range e.Items {
+		errs[i] = item
+	}
+	return errs
+}
+
+// CreatePaymentBatch creates many orders concurrently, keeping at most
+// WithBatchConcurrency requests in flight (DefaultBatchConcurrency unless
+// set) and honouring WithRateLimit. The responses are in input order, nil
+// for orders that got none.
+//
+// When any order fails a *BatchError lists each failure by index. Once ctx
+// is done no further orders are started and the remaining ones fail with
+// ctx.Err().
+func (c *Client) CreatePaymentBatch(ctx context.Context, params []*CreatePaymentParams, opts ...RequestOption) ([]*PaymentResponse, error) {
+	resps, errs := c.createPaymentBatch(ctx, params, opts...)
+
+	var batchErr *BatchError
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		if batchErr == nil {
+			batchErr = &BatchError{Total: len(params)}
+		}
+
+		orderID := params[i].OrderID
+		if resps[i] != nil && resps[i].GeneratedOrderID != "" {
+			orderID = resps[i].GeneratedOrderID
+		}
+		batchErr.Items = append(batchErr.Items, &BatchItemError{Index: i, OrderID: orderID, Err: err})
+	}
+
+	if batchErr != nil {
+		return resps, batchErr
+	}
+	return resps, nil
+}
+
+// createPaymentBatch runs createPayment for each of params on a bounded
+// worker pool and returns the responses and errors by input index
+func (c *Client) createPaymentBatch(ctx context.Context, params []*CreatePaymentParams, opts ...RequestOption) ([]*PaymentResponse, []error) {
+	resps := make([]*PaymentResponse, len(params))
+	errs := c.runBatch(ctx, len(params), func(i int) error {
+		var err error
+		resps[i], err = c.createPayment(ctx, params[i], opts...)
+		return err
+	})
+	return resps, errs
+}
+
+// runBatch calls fn for each index below n on at most batchConcurrency
+// goroutines and returns the errors by index. Once ctx is done no further
+// calls are started and the remaining indexes fail with ctx.Err().
+func (c *Client) runBatch(ctx context.Context, n int, fn func(i int) error) []error {
+	errs := make([]error, n)
+
+	workers := c.batchConcurrency
+	if workers > n {
+		workers = n
+	}
+
+	var next int64 = -1
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(atomic.AddInt64(&next, 1))
+				if i >= n {
+					return
+				}
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					continue
+				}
+				errs[i] = fn(i)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// QueryBatchError is returned by QueryPaymentsByTradeIDs when any query
+// failed. Errors holds each failure by trade ID; errors.Is and errors.As
+// see every one.
+type QueryBatchError struct {
+	Total  int
+	Errors map[string]error
+}
+
+func (e *QueryBatchError) Error() string {
+	msg := fmt.Sprintf("cryptomepay: %d of %d queries failed", len(e.Errors), e.Total)
+	for _, id := range e.tradeIDs() {
+		msg += fmt.Sprintf("; %s: %v", id, e.Errors[id])
+	}
+	return msg
+}
+
+func (e *QueryBatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, id := range e.tradeIDs() {
+		errs = append(errs, e.Errors[id])
+	}
+	return errs
+}
+
+// tradeIDs returns the failed trade IDs in sorted order
+func (e *QueryBatchError) tradeIDs() []string {
+	ids := make([]string, 0, len(e.Errors))
+	for id := range e.Errors {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// QueryPaymentsByTradeIDs queries many orders by trade_id, returning the
+// found orders by trade ID. Duplicate IDs are queried once. The queries run
+// like CreatePaymentBatch, at most WithBatchConcurrency at a time and
+// honouring WithRateLimit, since the gateway has no batch query endpoint.
+//
+// When any query fails a *QueryBatchError lists each failure by trade ID,
+// ErrOrderNotFound for unknown orders, and the other orders are still
+// returned. An empty ID fails the call before anything is sent.
+func (c *Client) QueryPaymentsByTradeIDs(ctx context.Context, tradeIDs []string, opts ...RequestOption) (map[string]*OrderData, error) {
+	seen := make(map[string]bool, len(tradeIDs))
+	var ids []string
+	for _, id := range tradeIDs {
+		if id == "" {
+			return nil, &ValidationError{Field: "trade_id", Message: "is required"}
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	resps := make([]*OrderResponse, len(ids))
+	errs := c.runBatch(ctx, len(ids), func(i int) error {
+		var err error
+		resps[i], err = c.queryOrder(ctx, "trade_id", ids[i], opts...)
+		return err
+	})
+
+	orders := make(map[string]*OrderData, len(ids))
+	var batchErr *QueryBatchError
+	for i, id := range ids {
+		if errs[i] != nil {
+			if batchErr == nil {
+				batchErr = &QueryBatchError{Total: len(ids), Errors: map[string]error{}}
+			}
+			batchErr.Errors[id] = errs[i]
+			continue
+		}
+		if resps[i].Data != nil {
+			orders[id] = resps[i].Data
+		}
+	}
+
+	if batchErr != nil {
+		return orders, batchErr
+	}
+	return orders, nil
+}
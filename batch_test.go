@@ -0,0 +1,193 @@
+package cryptomepay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func batchParams(n int) []*CreatePaymentParams {
+	params := make([]*CreatePaymentParams, n)
+	for i := range params {
+		params[i] = &CreatePaymentParams{OrderID: fmt.Sprintf("ORDER_%03d", i), Amount: 1, NotifyURL: "https://example.com/webhook"}
+	}
+	return params
+}
+
+func TestCreatePaymentBatch(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		orderID := body["order_id"].(string)
+		if orderID == "ORDER_003" {
+			json.NewEncoder(w).Encode(PaymentResponse{StatusCode: ErrCodeOrderExists, Message: "order exists"})
+			return
+		}
+		json.NewEncoder(w).Encode(PaymentResponse{StatusCode: 200, Data: &PaymentData{TradeID: "CP_" + orderID, OrderID: orderID}})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL), WithBatchConcurrency(3))
+	params := batchParams(20)
+	params[7].Amount = 0
+
+	resps, err := client.CreatePaymentBatch(context.Background(), params)
+	assert.Len(t, resps, 20)
+	assert.LessOrEqual(t, maxInFlight, int32(3))
+	for i, resp := range resps {
+		switch i {
+		case 3:
+			assert.Equal(t, ErrCodeOrderExists, resp.StatusCode)
+		case 7:
+			assert.Nil(t, resp)
+		default:
+			assert.Equal(t, fmt.Sprintf("CP_ORDER_%03d", i), resp.Data.TradeID)
+		}
+	}
+
+	var batchErr *BatchError
+	if assert.ErrorAs(t, err, &batchErr) {
+		assert.Equal(t, 20, batchErr.Total)
+		if assert.Len(t, batchErr.Items, 2) {
+			assert.Equal(t, 3, batchErr.Items[0].Index)
+			assert.Equal(t, "ORDER_003", batchErr.Items[0].OrderID)
+			assert.Equal(t, 7, batchErr.Items[1].Index)
+		}
+	}
+
+	var apiErr *APIError
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, ErrCodeOrderExists, apiErr.StatusCode)
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+}
+
+func TestCreatePaymentBatchCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 2 {
+			cancel()
+		}
+		json.NewEncoder(w).Encode(PaymentResponse{StatusCode: 200, Data: &PaymentData{TradeID: "CP1"}})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL), WithBatchConcurrency(1))
+	resps, err := client.CreatePaymentBatch(ctx, batchParams(10))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotNil(t, resps[0])
+	assert.Less(t, atomic.LoadInt32(&calls), int32(4))
+
+	var batchErr *BatchError
+	if assert.ErrorAs(t, err, &batchErr) {
+		assert.GreaterOrEqual(t, len(batchErr.Items), 8)
+		assert.True(t, errors.Is(batchErr.Items[len(batchErr.Items)-1], context.Canceled))
+	}
+}
+
+func TestCreatePaymentBatchRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(PaymentResponse{StatusCode: 200, Data: &PaymentData{TradeID: "CP1"}})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithBatchConcurrency(10),
+		WithRateLimit(50, 1),
+	)
+
+	start := time.Now()
+	_, err := client.CreatePaymentBatch(context.Background(), batchParams(6))
+	assert.NoError(t, err)
+	// One token up front, then one every 20ms
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+}
+
+func TestQueryPaymentsByTradeIDs(t *testing.T) {
+	var calls, inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		tradeID := r.URL.Query().Get("trade_id")
+		switch tradeID {
+		case "CP_MISSING":
+			json.NewEncoder(w).Encode(OrderResponse{StatusCode: 200})
+		case "CP_BAD":
+			json.NewEncoder(w).Encode(OrderResponse{StatusCode: ErrCodeInvalidOrderID, Message: "invalid order id"})
+		default:
+			json.NewEncoder(w).Encode(OrderResponse{StatusCode: 200, Data: &OrderData{TradeID: tradeID, Status: StatusPaid}})
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL), WithBatchConcurrency(3))
+
+	ids := []string{"CP_BAD", "CP_MISSING"}
+	for i := 0; i < 10; i++ {
+		ids = append(ids, fmt.Sprintf("CP_%02d", i), fmt.Sprintf("CP_%02d", i))
+	}
+
+	orders, err := client.QueryPaymentsByTradeIDs(context.Background(), ids)
+	assert.Equal(t, int32(12), calls, "duplicates are queried once")
+	assert.LessOrEqual(t, maxInFlight, int32(3))
+	assert.Len(t, orders, 10)
+	assert.Equal(t, StatusPaid, orders["CP_04"].Status)
+
+	var batchErr *QueryBatchError
+	if assert.ErrorAs(t, err, &batchErr) {
+		assert.Equal(t, 12, batchErr.Total)
+		assert.Len(t, batchErr.Errors, 2)
+		assert.ErrorIs(t, batchErr.Errors["CP_MISSING"], ErrOrderNotFound)
+		assert.Contains(t, err.Error(), "2 of 12 queries failed; CP_BAD: ")
+	}
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+	var apiErr *APIError
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, ErrCodeInvalidOrderID, apiErr.StatusCode)
+	}
+}
+
+func TestQueryPaymentsByTradeIDsValidation(t *testing.T) {
+	client := NewClient("sk_test_key", "test_secret")
+
+	orders, err := client.QueryPaymentsByTradeIDs(context.Background(), []string{"CP1", ""})
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Nil(t, orders)
+
+	orders, err = client.QueryPaymentsByTradeIDs(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, orders)
+}
@@ -0,0 +1,174 @@
+package cryptomepay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// BulkPaymentResult is the outcome for one order of a bulk creation
+type BulkPaymentResult struct {
+	OrderID    string       `json:"order_id"`
+	StatusCode int          `json:"status_code"`
+	Message    string       `json:"message"`
+	Data       *PaymentData `json:"data"`
+}
+
+// BulkPaymentData holds the per-order results in request order
+type BulkPaymentData struct {
+	Results []BulkPaymentResult `json:"results"`
+}
+
+// BulkPaymentResponse is the API response for bulk order creation
+type BulkPaymentResponse struct {
+	StatusCode int              `json:"status_code"`
+	Message    string           `json:"message"`
+	Data       *BulkPaymentData `json:"data"`
+	RequestID  string           `json:"request_id"`
+
+	ResponseMeta
+}
+
+// ByOrderID returns the results keyed by order_id
+func (r *BulkPaymentResponse) ByOrderID() map[string]*BulkPaymentResult {
+	out := make(map[string]*BulkPaymentResult)
+	if r.Data == nil {
+		return out
+	}
+	for i := range r.Data.Results {
+		out[r.Data.Results[i].OrderID] = &r.Data.Results[i]
+	}
+	return out
+}
+
+// BulkCreatePayments creates several orders with one signed request to the
+// gateway's bulk endpoint.
+//
+// The orders are signed as a single "orders" parameter holding the compact
+// JSON array of each order's fields with sorted keys and formatted amounts,
+// alongside api_key, timestamp and nonce. If the gateway has no bulk
+// endpoint the orders are created one by one and the results are combined
+// into the same response shape. When ctx is done part way through, that
+// response is returned along with ctx.Err(), so the orders already created
+// can be told from those never sent, whose results carry the context error
+// and no status code.
+func (c *Client) BulkCreatePayments(ctx context.Context, params []*CreatePaymentParams, opts ...RequestOption) (*BulkPaymentResponse, error) {
+	if len(params) == 0 {
+		return nil, &ValidationError{Field: "orders", Message: "at least one order is required"}
+	}
+
+	chains := c.supportedChainsForCheck(ctx)
+	orders := make([]map[string]string, len(params))
+	for i, p := range params {
+		prepared, _, err := c.preparePayment(p)
+		if err == nil {
+			err = checkChainLimits(chains, prepared)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("order %d: %w", i, err)
+		}
+		orders[i] = paymentFields(prepared)
+	}
+
+	ordersJSON, err := signingJSON(orders)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal orders: %w", err)
+	}
+
+	timestamp := c.timestamp()
+	nonce, err := c.nonce()
+	if err != nil {
+		return nil, err
+	}
+
+	creds := c.credentials()
+	paramsMap := map[string]string{
+		"api_key":   creds.apiKey,
+		"timestamp": timestamp,
+		"nonce":     nonce,
+		"orders":    string(ordersJSON),
+	}
+
+	signature, err := c.generateSignature(creds, paramsMap)
+	if err != nil {
+		return nil, err
+	}
+
+	// The orders are sent as an array, not the signed JSON string
+	body := make(map[string]interface{}, len(paramsMap)+1)
+	for k, v := range paramsMap {
+		body[k] = v
+	}
+	body["orders"] = orders
+	body["signature"] = signature
+
+	var resp BulkPaymentResponse
+	err = c.request(ctx, "POST", "/order/bulk-create-transaction", body, &resp, append([]RequestOption{withAPIKey(creds.apiKey)}, opts...)...)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.HTTPStatus {
+		case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+			return c.bulkCreateFanOut(ctx, params, opts...)
+		}
+	}
+	return &resp, err
+}
+
+// signingJSON encodes v as compact JSON for a signed parameter. Unlike
+// json.Marshal it leaves &, < and > unescaped, so a notify_url with a query
+// string is signed as sent rather than as \u0026.
+func signingJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// bulkCreateFanOut creates the orders concurrently, as CreatePaymentBatch
+// does, when the bulk endpoint is unavailable
+func (c *Client) bulkCreateFanOut(ctx context.Context, params []*CreatePaymentParams, opts ...RequestOption) (*BulkPaymentResponse, error) {
+	resps, errs := c.createPaymentBatch(ctx, params, opts...)
+
+	results := make([]BulkPaymentResult, len(params))
+	for i, p := range params {
+		results[i] = bulkResult(p.OrderID, resps[i], errs[i])
+	}
+
+	// Orders created before ctx was done exist on the gateway, so their
+	// results are kept alongside the error
+	return &BulkPaymentResponse{
+		StatusCode: 200,
+		Message:    "success",
+		Data:       &BulkPaymentData{Results: results},
+	}, ctx.Err()
+}
+
+// bulkResult converts a single CreatePayment outcome into a bulk result
+func bulkResult(orderID string, resp *PaymentResponse, err error) BulkPaymentResult {
+	result := BulkPaymentResult{OrderID: orderID}
+	if resp != nil {
+		result.StatusCode = resp.StatusCode
+		result.Message = resp.Message
+		result.Data = resp.Data
+		if resp.GeneratedOrderID != "" {
+			result.OrderID = resp.GeneratedOrderID
+		}
+	}
+
+	var apiErr *APIError
+	switch {
+	case errors.As(err, &apiErr):
+		result.StatusCode = apiErr.StatusCode
+		result.Message = apiErr.Message
+	case err != nil:
+		result.Message = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,151 @@
+package cryptomepay
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkCreatePayments(t *testing.T) {
+	client := NewClient("sk_test_key", "test_secret")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/order/bulk-create-transaction", r.URL.Path)
+
+		var body struct {
+			APIKey    string              `json:"api_key"`
+			Timestamp string              `json:"timestamp"`
+			Nonce     string              `json:"nonce"`
+			Orders    []map[string]string `json:"orders"`
+			Signature string              `json:"signature"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		// The signature covers the canonical orders array, with the & of
+		// a query string left unescaped
+		ordersJSON := `[{"amount":"100.00","notify_url":"https://example.com/webhook","order_id":"O1"},` +
+			`{"amount":"5.00","notify_url":"https://example.com/webhook?a=1&b=2","order_id":"O2"}]`
+		expected := client.calculateSignature(map[string]string{
+			"api_key":   body.APIKey,
+			"timestamp": body.Timestamp,
+			"nonce":     body.Nonce,
+			"orders":    ordersJSON,
+		})
+		assert.Equal(t, expected, body.Signature)
+		assert.Equal(t, "100.00", body.Orders[0]["amount"])
+
+		json.NewEncoder(w).Encode(BulkPaymentResponse{
+			StatusCode: 200,
+			Data: &BulkPaymentData{Results: []BulkPaymentResult{
+				{OrderID: "O1", StatusCode: 200, Data: &PaymentData{TradeID: "CP1"}},
+				{OrderID: "O2", StatusCode: ErrCodeOrderExists, Message: "order exists"},
+			}},
+		})
+	}))
+	defer server.Close()
+
+	client = NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	resp, err := client.BulkCreatePayments(context.Background(), []*CreatePaymentParams{
+		{OrderID: "O1", Amount: 100, NotifyURL: "https://example.com/webhook"},
+		{OrderID: "O2", Amount: 5, NotifyURL: "https://example.com/webhook?a=1&b=2"},
+	})
+	assert.NoError(t, err)
+
+	byID := resp.ByOrderID()
+	assert.Equal(t, "CP1", byID["O1"].Data.TradeID)
+	assert.Equal(t, ErrCodeOrderExists, byID["O2"].StatusCode)
+}
+
+func TestBulkCreatePaymentsFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/order/bulk-create-transaction" {
+			http.NotFound(w, r)
+			return
+		}
+
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["order_id"] == "DUP" {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(ErrorResponse{StatusCode: ErrCodeOrderExists, Message: "order exists"})
+			return
+		}
+		json.NewEncoder(w).Encode(PaymentResponse{StatusCode: 200, Data: &PaymentData{OrderID: body["order_id"].(string)}})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	resp, err := client.BulkCreatePayments(context.Background(), []*CreatePaymentParams{
+		{OrderID: "O1", Amount: 1, NotifyURL: "https://example.com/webhook"},
+		{OrderID: "DUP", Amount: 1, NotifyURL: "https://example.com/webhook"},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Data.Results, 2)
+	assert.Equal(t, 200, resp.Data.Results[0].StatusCode)
+	assert.Equal(t, ErrCodeOrderExists, resp.Data.Results[1].StatusCode)
+	assert.Equal(t, "DUP", resp.Data.Results[1].OrderID)
+}
+
+func TestBulkCreatePaymentsFallbackCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The first order is created, then the caller gives up during the second
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/order/bulk-create-transaction" {
+			http.NotFound(w, r)
+			return
+		}
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["order_id"] == "O2" {
+			cancel()
+			<-r.Context().Done()
+			return
+		}
+		json.NewEncoder(w).Encode(PaymentResponse{StatusCode: 200, Data: &PaymentData{TradeID: "CP1"}})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL), WithBatchConcurrency(1))
+
+	resp, err := client.BulkCreatePayments(ctx, []*CreatePaymentParams{
+		{OrderID: "O1", Amount: 1, NotifyURL: "https://example.com/webhook"},
+		{OrderID: "O2", Amount: 1, NotifyURL: "https://example.com/webhook"},
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	if assert.NotNil(t, resp) && assert.Len(t, resp.Data.Results, 2) {
+		assert.Equal(t, 200, resp.Data.Results[0].StatusCode)
+		assert.Equal(t, "CP1", resp.Data.Results[0].Data.TradeID)
+		assert.Zero(t, resp.Data.Results[1].StatusCode)
+		assert.Equal(t, "O2", resp.Data.Results[1].OrderID)
+		assert.Contains(t, resp.Data.Results[1].Message, "context canceled")
+	}
+}
+
+func TestBulkCreatePaymentsValidation(t *testing.T) {
+	client := NewClient("sk_test_key", "test_secret")
+
+	_, err := client.BulkCreatePayments(context.Background(), nil)
+	assert.Error(t, err)
+
+	_, err = client.BulkCreatePayments(context.Background(), []*CreatePaymentParams{
+		{OrderID: "O1", Amount: 1, NotifyURL: "http://insecure.example.com"},
+	})
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+}
+
+func TestSigningJSON(t *testing.T) {
+	b, err := signingJSON([]map[string]string{paymentFields(&CreatePaymentParams{
+		OrderID: "ORDER_001", Amount: 100, NotifyURL: "https://x.com/cb?a=1&b=2",
+	})})
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"amount":"100.00","notify_url":"https://x.com/cb?a=1&b=2","order_id":"ORDER_001"}]`, string(b))
+}
@@ -0,0 +1,65 @@
+package cryptomepay
+
+import (
+	"sync"
+	"time"
+)
+
+// ttlCache holds one response per api key for ttl, for WithMerchantCache
+// and WithSupportedChainsCache. Values are copied on the way in and out so
+// callers cannot change a cached one.
+type ttlCache[T any] struct {
+	ttl  time.Duration
+	copy func(T) T
+
+	mu      sync.Mutex
+	entries map[string]ttlEntry[T]
+
+	// refreshMu serializes fetches so concurrent misses share one request
+	refreshMu sync.Mutex
+}
+
+type ttlEntry[T any] struct {
+	value     T
+	expiresAt time.Time
+}
+
+func newTTLCache[T any](ttl time.Duration, copy func(T) T) *ttlCache[T] {
+	return &ttlCache[T]{ttl: ttl, copy: copy, entries: map[string]ttlEntry[T]{}}
+}
+
+func (m *ttlCache[T]) get(apiKey string) (T, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.entries[apiKey]
+	if !ok || !time.Now().Before(entry.expiresAt) {
+		var zero T
+		return zero, false
+	}
+	return m.copy(entry.value), true
+}
+
+func (m *ttlCache[T]) put(apiKey string, value T) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[apiKey] = ttlEntry[T]{value: m.copy(value), expiresAt: time.Now().Add(m.ttl)}
+}
+
+// getOrFetch returns the cached value for apiKey, with hit set, or the
+// result of fetch. Concurrent misses wait for one fetch rather than each
+// sending a request; fetch is expected to put what it gets.
+func (m *ttlCache[T]) getOrFetch(apiKey string, fetch func() (T, error)) (value T, hit bool, err error) {
+	if value, ok := m.get(apiKey); ok {
+		return value, true, nil
+	}
+
+	m.refreshMu.Lock()
+	defer m.refreshMu.Unlock()
+
+	// Another caller may have fetched while we waited
+	if value, ok := m.get(apiKey); ok {
+		return value, true, nil
+	}
+	value, err = fetch()
+	return value, false, err
+}
@@ -0,0 +1,76 @@
+package cryptomepay
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTTLCache(t *testing.T) {
+	cache := newTTLCache(20*time.Millisecond, func(v []int) []int { return append([]int(nil), v...) })
+
+	_, ok := cache.get("key")
+	assert.False(t, ok)
+
+	value := []int{1}
+	cache.put("key", value)
+	value[0] = 2
+
+	// Values are copied in and out
+	got, ok := cache.get("key")
+	assert.True(t, ok)
+	assert.Equal(t, []int{1}, got)
+	got[0] = 3
+	got, _ = cache.get("key")
+	assert.Equal(t, []int{1}, got)
+
+	_, ok = cache.get("other")
+	assert.False(t, ok)
+
+	time.Sleep(30 * time.Millisecond)
+	_, ok = cache.get("key")
+	assert.False(t, ok)
+}
+
+func TestTTLCacheGetOrFetch(t *testing.T) {
+	cache := newTTLCache(time.Minute, func(v int) int { return v })
+
+	var fetches int32
+	fetch := func() (int, error) {
+		atomic.AddInt32(&fetches, 1)
+		time.Sleep(10 * time.Millisecond)
+		cache.put("key", 42)
+		return 42, nil
+	}
+
+	// Concurrent misses share one fetch
+	var wg sync.WaitGroup
+	var hits int32
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, hit, err := cache.getOrFetch("key", fetch)
+			assert.NoError(t, err)
+			assert.Equal(t, 42, value)
+			if hit {
+				atomic.AddInt32(&hits, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), fetches)
+	assert.Equal(t, int32(4), hits)
+
+	// Failed fetches are not cached
+	failing := newTTLCache(time.Minute, func(v int) int { return v })
+	_, hit, err := failing.getOrFetch("key", func() (int, error) { return 0, errors.New("down") })
+	assert.False(t, hit)
+	assert.EqualError(t, err, "down")
+	_, ok := failing.get("key")
+	assert.False(t, ok)
+}
@@ -0,0 +1,60 @@
+package cryptomepay
+
+import "context"
+
+// CancelOrder cancels a pending order by trade_id so its wallet address is
+// released, and returns the updated order.
+//
+// The request is signed like CreatePayment. A paid order fails with an
+// *APIError with code ErrCodeOrderAlreadyPaid, and an unknown one with
+// ErrCodeOrderNotFound.
+func (c *Client) CancelOrder(tradeID string, opts ...RequestOption) (*OrderResponse, error) {
+	return c.cancelOrder(context.Background(), "trade_id", tradeID, opts...)
+}
+
+// CancelOrderByOrderID cancels a pending order by order_id, see CancelOrder
+func (c *Client) CancelOrderByOrderID(orderID string, opts ...RequestOption) (*OrderResponse, error) {
+	return c.cancelOrder(context.Background(), "order_id", orderID, opts...)
+}
+
+// cancelOrder cancels the order identified by the given id field.
+// Cancelling is idempotent, so the call carries a key and is retried like
+// any other request.
+func (c *Client) cancelOrder(ctx context.Context, field, id string, opts ...RequestOption) (*OrderResponse, error) {
+	if id == "" {
+		return nil, &ValidationError{Field: field, Message: "is required"}
+	}
+
+	nonce, err := c.nonce()
+	if err != nil {
+		return nil, err
+	}
+
+	creds := c.credentials()
+	paramsMap := map[string]string{
+		"api_key":   creds.apiKey,
+		"timestamp": c.timestamp(),
+		"nonce":     nonce,
+		field:       id,
+	}
+
+	signature, err := c.generateSignature(creds, paramsMap)
+	if err != nil {
+		return nil, err
+	}
+
+	body := make(map[string]interface{}, len(paramsMap)+1)
+	for k, v := range paramsMap {
+		body[k] = v
+	}
+	body["signature"] = signature
+
+	opts = append([]RequestOption{WithIdempotencyKey("cancel-" + field + "-" + id), withAPIKey(creds.apiKey)}, opts...)
+
+	var resp OrderResponse
+	err = c.request(ctx, "POST", "/order/cancel-transaction", body, &resp, opts...)
+	if err == nil && resp.StatusCode == 200 && resp.Data == nil {
+		err = ErrOrderNotFound
+	}
+	return &resp, err
+}
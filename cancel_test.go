@@ -0,0 +1,87 @@
+package cryptomepay
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCancelOrder(t *testing.T) {
+	client := NewClientWithOptions("sk_test_key", "test_secret")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/order/cancel-transaction", r.URL.Path)
+		assert.Equal(t, "cancel-trade_id-CP1", r.Header.Get("Idempotency-Key"))
+
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		assert.Equal(t, "CP1", body["trade_id"])
+		assert.NotEmpty(t, body["nonce"])
+
+		signature := body["signature"]
+		delete(body, "signature")
+		assert.Equal(t, client.calculateSignature(body), signature)
+
+		json.NewEncoder(w).Encode(OrderResponse{StatusCode: 200, Data: &OrderData{TradeID: "CP1", Status: StatusExpired}})
+	}))
+	defer server.Close()
+	client = client.Clone(WithBaseURL(server.URL))
+
+	resp, err := client.CancelOrder("CP1")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusExpired, resp.Data.Status)
+}
+
+func TestCancelOrderByOrderID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		assert.Equal(t, "ORDER_001", body["order_id"])
+		assert.Empty(t, body["trade_id"])
+
+		json.NewEncoder(w).Encode(OrderResponse{StatusCode: 200, Data: &OrderData{TradeID: "CP1", OrderID: "ORDER_001"}})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+	resp, err := client.CancelOrderByOrderID("ORDER_001")
+	assert.NoError(t, err)
+	assert.Equal(t, "CP1", resp.Data.TradeID)
+}
+
+func TestCancelOrderErrors(t *testing.T) {
+	tests := []struct {
+		tradeID string
+		code    int
+	}{
+		{"CP_PAID", ErrCodeOrderAlreadyPaid},
+		{"CP_MISSING", ErrCodeOrderNotFound},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		code := ErrCodeOrderNotFound
+		if body["trade_id"] == "CP_PAID" {
+			code = ErrCodeOrderAlreadyPaid
+		}
+		json.NewEncoder(w).Encode(OrderResponse{StatusCode: code, Message: "cannot cancel"})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+	for _, tc := range tests {
+		_, err := client.CancelOrder(tc.tradeID)
+		var apiErr *APIError
+		if assert.ErrorAs(t, err, &apiErr, tc.tradeID) {
+			assert.Equal(t, tc.code, apiErr.StatusCode)
+		}
+	}
+
+	_, err := client.CancelOrder("")
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+}
@@ -0,0 +1,34 @@
+package cryptomepay
+
+// ChainType identifies a blockchain network, one of the Chain* constants
+type ChainType string
+
+// chainDisplayNames holds the human readable name of each known chain
+var chainDisplayNames = map[ChainType]string{
+	ChainTRC20:    "TRON (TRC20)",
+	ChainBSC:      "BNB Smart Chain",
+	ChainPolygon:  "Polygon",
+	ChainETH:      "Ethereum",
+	ChainArbitrum: "Arbitrum One",
+}
+
+// AllChains returns the chains this SDK knows about, in a stable order
+// suitable for a selection list
+func AllChains() []ChainType {
+	return []ChainType{ChainTRC20, ChainBSC, ChainPolygon, ChainETH, ChainArbitrum}
+}
+
+// Valid reports whether c is one of the Chain* constants
+func (c ChainType) Valid() bool {
+	_, ok := chainDisplayNames[c]
+	return ok
+}
+
+// DisplayName returns a human readable name such as "BNB Smart Chain". An
+// unknown chain is returned as is.
+func (c ChainType) DisplayName() string {
+	if name, ok := chainDisplayNames[c]; ok {
+		return name
+	}
+	return string(c)
+}
@@ -0,0 +1,32 @@
+package cryptomepay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllChainsValid(t *testing.T) {
+	chains := AllChains()
+	assert.Equal(t, []ChainType{"TRC20", "BSC", "POLYGON", "ETH", "ARBITRUM"}, chains)
+	for _, c := range chains {
+		assert.True(t, c.Valid(), c)
+		assert.NotEqual(t, string(c), c.DisplayName())
+	}
+
+	assert.False(t, ChainType("SOLANA").Valid())
+	assert.False(t, ChainType("").Valid())
+	assert.False(t, ChainType("bsc").Valid())
+}
+
+func TestChainDisplayName(t *testing.T) {
+	assert.Equal(t, "BNB Smart Chain", ChainType(ChainBSC).DisplayName())
+	assert.Equal(t, "TRON (TRC20)", ChainType(ChainTRC20).DisplayName())
+	assert.Equal(t, "SOLANA", ChainType("SOLANA").DisplayName())
+}
+
+func TestChainConstantsAssignable(t *testing.T) {
+	var s string = ChainBSC
+	params := CreatePaymentParams{ChainType: ChainBSC}
+	assert.Equal(t, s, string(params.ChainType))
+}
@@ -16,7 +16,7 @@
 package cryptomepay
 
 import (
-	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
@@ -25,11 +25,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"net/mail"
 	"net/url"
+	"reflect"
 	"sort"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
+
+	"golang.org/x/time/rate"
 )
 
 // Version is the SDK version
@@ -38,7 +45,15 @@ const Version = "1.0.0"
 // API Base URL
 const ProductionURL = "https://api.cryptomepay.com/api/v1"
 
-// Chain types
+// DefaultMaxPageSize is the largest page size sent to the API by default
+const DefaultMaxPageSize = 100
+
+// MaxSplitPageSize is the largest PageSize ListOrders merges from several
+// server pages
+const MaxSplitPageSize = 10000
+
+// Chain types. The constants are untyped so they can be used wherever a
+// ChainType or a plain string is expected.
 const (
 	ChainTRC20    = "TRC20"
 	ChainBSC      = "BSC"
@@ -47,19 +62,113 @@ const (
 	ChainArbitrum = "ARBITRUM"
 )
 
-// Payment status codes
+// Confirmation priorities for CreatePaymentParams.Priority. Priority is
+// passed through to the gateway; a backend without fee tiers ignores it and
+// leaves the PaymentData confirmation window zero.
 const (
-	StatusPending = 1
-	StatusPaid    = 2
-	StatusExpired = 3
+	PriorityEconomy  = "economy"
+	PriorityStandard = "standard"
+	PriorityFast     = "fast"
 )
 
-// Client is the Cryptome Pay API client
+// Payment status codes.
+//
+// The API reports only these three states. Funds that have arrived on-chain
+// but are not yet confirmed by the gateway (see ErrCodeChainMonitoringDelay)
+// remain StatusPending until the order is marked paid, as do orders paid
+// only in part; OrderData.IsPartiallyPaid tells those apart.
+const (
+	StatusPending PaymentStatus = 1
+	StatusPaid    PaymentStatus = 2
+	StatusExpired PaymentStatus = 3
+)
+
+// Client is the Cryptome Pay API client.
+//
+// A Client is safe for concurrent use by multiple goroutines and is meant to
+// be shared. Its configuration, including the base URL, is fixed once
+// NewClientWithOptions returns; use Clone to derive a client for another
+// environment or tenant rather than changing a shared one.
 type Client struct {
-	apiKey     string
-	apiSecret  string
-	baseURL    string
-	httpClient *http.Client
+	apiKey    string
+	apiSecret string
+
+	// credentialProvider replaces apiKey and apiSecret when set
+	credentialProvider func() (apiKey, apiSecret string)
+
+	// apiKeyPrefixes are checked by NewClientChecked, see WithAPIKeyPrefix
+	apiKeyPrefixes []string
+
+	baseURL     string
+	httpClient  *http.Client
+	maxPageSize int
+
+	perAttemptTimeout time.Duration
+
+	maxAttempts     int
+	retryBaseDelay  time.Duration
+	retryMaxDelay   time.Duration
+	retryJitter     Jitter
+	honorRetryAfter bool
+
+	limiter *rate.Limiter
+
+	batchConcurrency int
+
+	checkChainType   bool
+	checkChainLimits bool
+
+	logger Logger
+
+	observer Observer
+
+	// requireHTTPSNotify overrides the default HTTPS policy when set
+	requireHTTPSNotify *bool
+
+	autoOrderID func() string
+
+	// nonceSource replaces generateNonce for signed requests when set
+	nonceSource func() string
+
+	// timeOffset and clockSkew correct the clock of signed requests
+	timeOffset time.Duration
+	clockSkew  *clockSkew
+
+	validateResponses bool
+
+	// responseSignatureHeader enables body signature checks when set
+	responseSignatureHeader string
+
+	errorOnNon200 bool
+
+	middleware []Middleware
+
+	// webhookVerifiedHook is called after each webhook signature check
+	webhookVerifiedHook func(payload *WebhookPayload, ok bool)
+
+	// signer replaces HMAC-SHA256 over apiSecret when set
+	signer Signer
+
+	// userAgent is appended to the SDK's User-Agent when set
+	userAgent string
+
+	defaultHeaders http.Header
+
+	merchantCache *ttlCache[*MerchantResponse]
+	chainCache    *ttlCache[*ChainListResponse]
+
+	// requestCompression is the smallest body gzipped, 0 for none
+	requestCompression int
+
+	// maxResponseSize bounds response bodies before and after decoding
+	maxResponseSize int64
+
+	// transportOptions configure the transport, see configureTransport
+	transportOptions []func(*http.Transport)
+	transportErr     error
+
+	auth      *tokenState
+	lifecycle *lifecycle
 }
 
 // NewClient creates a new Cryptome Pay client with default settings
@@ -71,6 +180,15 @@ func NewClient(apiKey, apiSecret string) *Client {
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		maxPageSize:      DefaultMaxPageSize,
+		maxResponseSize:  DefaultMaxResponseSize,
+		batchConcurrency: DefaultBatchConcurrency,
+		errorOnNon200:    true,
+		checkChainType:   true,
+		logger:           nopLogger{},
+		observer:         nopObserver{},
+		auth:             &tokenState{},
+		lifecycle:        &lifecycle{},
 	}
 }
 
@@ -84,17 +202,170 @@ func WithBaseURL(baseURL string) Option {
 	}
 }
 
-// WithHTTPClient sets a custom HTTP client
+// WithHTTPClient sets a custom HTTP client. Options applied after it, such
+// as WithTimeout and WithTransport, change a copy and never the caller's
+// client.
 func WithHTTPClient(httpClient *http.Client) Option {
 	return func(c *Client) {
 		c.httpClient = httpClient
 	}
 }
 
-// WithTimeout sets the HTTP client timeout
+// WithTransport sets the transport used for requests, for example to raise
+// MaxIdleConnsPerHost (2 in the default transport) for high-throughput
+// order creation. The HTTP client is copied, so one passed to WithHTTPClient
+// is not modified. Options such as WithProxy given before it are applied to
+// a copy of transport.
+func WithTransport(transport *http.Transport) Option {
+	return func(c *Client) {
+		httpClient := *c.httpClient
+		httpClient.Transport = transport
+		c.httpClient = &httpClient
+		if len(c.transportOptions) > 0 {
+			c.applyTransportOptions()
+		}
+	}
+}
+
+// WithUserAgent appends ua to the SDK's User-Agent, giving
+// "cryptomepay-go/<version> <ua>", for example to tell services apart
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+// WithDefaultHeaders adds headers sent with every request, for example a
+// partner identifier. Headers from WithRequestHeader take precedence.
+// Authorization, Content-Type, Accept, User-Agent, Idempotency-Key and
+// ClientRequestIDHeader are set by the client and cannot be overridden this
+// way.
+func WithDefaultHeaders(headers http.Header) Option {
+	return func(c *Client) {
+		merged := c.defaultHeaders.Clone()
+		if merged == nil {
+			merged = http.Header{}
+		}
+		for k, v := range headers {
+			merged[http.CanonicalHeaderKey(k)] = append([]string(nil), v...)
+		}
+		c.defaultHeaders = merged
+	}
+}
+
+// WithTimeout sets the default timeout of every call, 30 seconds unless
+// changed. WithRequestTimeout overrides it for a single call. The HTTP
+// client is copied, so one passed to WithHTTPClient is not modified.
 func WithTimeout(timeout time.Duration) Option {
 	return func(c *Client) {
-		c.httpClient.Timeout = timeout
+		httpClient := *c.httpClient
+		httpClient.Timeout = timeout
+		c.httpClient = &httpClient
+	}
+}
+
+// WithMaxPageSize sets the largest page size sent to the API.
+// ListOrders requests with a larger PageSize are split into several
+// server-sized requests and merged. Values <= 0 are ignored.
+func WithMaxPageSize(size int) Option {
+	return func(c *Client) {
+		if size > 0 {
+			c.maxPageSize = size
+		}
+	}
+}
+
+// WithPerAttemptTimeout bounds each HTTP attempt of a call to timeout.
+// The call's context deadline still applies on top of it.
+func WithPerAttemptTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.perAttemptTimeout = timeout
+	}
+}
+
+// WithRequireHTTPSNotify controls whether CreatePayment rejects notify_url
+// and redirect_url values that are not HTTPS. It defaults to on when the
+// client targets ProductionURL and off otherwise, so plain http such as
+// http://localhost stays usable against sandbox or local gateways.
+func WithRequireHTTPSNotify(require bool) Option {
+	return func(c *Client) {
+		c.requireHTTPSNotify = &require
+	}
+}
+
+// WithAutoOrderID makes CreatePayment fill an empty OrderID using gen.
+// A nil gen uses DefaultOrderID. The generated id is reported on
+// PaymentResponse.GeneratedOrderID; an explicit OrderID is always kept.
+func WithAutoOrderID(gen func() string) Option {
+	return func(c *Client) {
+		if gen == nil {
+			gen = DefaultOrderID
+		}
+		c.autoOrderID = gen
+	}
+}
+
+// WithNonceSource makes signed requests use fn for their nonce instead of
+// 32 random hex characters, for example to pin it in tests or to follow a
+// mandated format. fn is called from concurrent goroutines and must return
+// a value the gateway has not seen before; a nil fn restores the default.
+func WithNonceSource(fn func() string) Option {
+	return func(c *Client) {
+		c.nonceSource = fn
+	}
+}
+
+// DefaultOrderID returns a unique id made of a UTC timestamp and random
+// suffix, e.g. ORDER_20251201103000_9f86d081884c7d65. Should the entropy
+// source fail, the suffix is built from the clock and a counter instead.
+func DefaultOrderID() string {
+	now := time.Now().UTC()
+	suffix, err := generateNonce()
+	if err != nil {
+		suffix = fmt.Sprintf("%08x%08x", uint32(now.UnixNano()), atomic.AddUint32(&orderIDCounter, 1))
+	}
+	return "ORDER_" + now.Format("20060102150405") + "_" + suffix[:16]
+}
+
+// WithResponseValidation makes CreatePayment check that the returned wallet
+// address (PaymentData.Token) is well formed for its chain, returning an
+// *AddressError alongside the response when it is not.
+func WithResponseValidation() Option {
+	return func(c *Client) {
+		c.validateResponses = true
+	}
+}
+
+// WithChainTypeCheck controls whether CreatePayment rejects a ChainType
+// that is not one of the Chain* constants. It is enabled by default;
+// disable it to use chains the gateway added after this SDK version.
+func WithChainTypeCheck(enabled bool) Option {
+	return func(c *Client) {
+		c.checkChainType = enabled
+	}
+}
+
+// WithErrorOnNon200 controls whether a response whose status_code is not
+// 200 is returned as an *APIError. It is enabled by default; the response
+// is returned alongside the error either way. Pass false to get only the
+// response and check StatusCode yourself.
+func WithErrorOnNon200(enabled bool) Option {
+	return func(c *Client) {
+		c.errorOnNon200 = enabled
+	}
+}
+
+// WithResponseSignatureVerification rejects any successful response whose
+// body HMAC, sent by the gateway in headerName, is missing or does not match,
+// returning a *ResponseSignatureError before the body is decoded. An empty
+// headerName selects DefaultResponseSignatureHeader. Error responses (HTTP
+// >= 400) are not checked, since proxies in front of the gateway may issue them.
+func WithResponseSignatureVerification(headerName string) Option {
+	return func(c *Client) {
+		if headerName == "" {
+			headerName = DefaultResponseSignatureHeader
+		}
+		c.responseSignatureHeader = headerName
 	}
 }
 
@@ -107,26 +378,97 @@ func NewClientWithOptions(apiKey, apiSecret string, opts ...Option) *Client {
 	return c
 }
 
+// Clone returns a copy of the client with opts applied on top of its
+// configuration. The copy shares the underlying transport, and so its
+// connection pool, but has its own http.Client so options like WithTimeout
+// do not affect the original. Bearer tokens from Authenticate are not
+// copied. Use WithCredentials to give the copy its own api key and secret.
+func (c *Client) Clone(opts ...Option) *Client {
+	clone := *c
+	httpClient := *c.httpClient
+	clone.httpClient = &httpClient
+
+	// Tokens belong to the original credentials, and shutting down the
+	// original must not stop the clone
+	clone.auth = &tokenState{}
+	clone.lifecycle = &lifecycle{}
+	if c.clockSkew != nil {
+		clone.clockSkew = &clockSkew{}
+	}
+	if c.limiter != nil {
+		clone.limiter = newLimiter(float64(c.limiter.Limit()), c.limiter.Burst())
+	}
+
+	for _, opt := range opts {
+		opt(&clone)
+	}
+	return &clone
+}
+
+// WithCredentials sets the api key and secret, replacing any
+// WithCredentialProvider
+func WithCredentials(apiKey, apiSecret string) Option {
+	return func(c *Client) {
+		c.apiKey = apiKey
+		c.apiSecret = apiSecret
+		c.credentialProvider = nil
+	}
+}
+
 // CreatePaymentParams holds parameters for creating a payment
 type CreatePaymentParams struct {
-	OrderID     string  `json:"order_id"`
-	Amount      float64 `json:"amount"`
-	NotifyURL   string  `json:"notify_url"`
-	RedirectURL string  `json:"redirect_url,omitempty"`
-	ChainType   string  `json:"chain_type,omitempty"`
+	OrderID     string    `json:"order_id"`
+	Amount      float64   `json:"amount"`
+	NotifyURL   string    `json:"notify_url"`
+	RedirectURL string    `json:"redirect_url,omitempty"`
+	ChainType   ChainType `json:"chain_type,omitempty"`
+
+	// AmountString is an exact decimal alternative to Amount, e.g. "19.99".
+	// When set it is signed and sent as written, padded to two decimals,
+	// avoiding float formatting. It may have at most two decimals and must
+	// agree with Amount if both are set.
+	AmountString string `json:"-"`
+
+	// CustomerEmail opts into a gateway-sent receipt for this order
+	CustomerEmail string `json:"customer_email,omitempty"`
+
+	// Priority selects a confirmation tier (PriorityEconomy, PriorityStandard
+	// or PriorityFast), trading customer wait time against fees
+	Priority string `json:"priority,omitempty"`
+
+	// IdempotencyKey is sent in the Idempotency-Key header so a repeated
+	// call returns the original order instead of creating another. When
+	// empty, a key derived from OrderID is used. WithIdempotencyKey takes
+	// precedence over both.
+	IdempotencyKey string `json:"-"`
 }
 
 // PaymentData holds payment response data
 type PaymentData struct {
-	TradeID        string  `json:"trade_id"`
-	OrderID        string  `json:"order_id"`
-	Amount         float64 `json:"amount"`
-	ActualAmount   float64 `json:"actual_amount"`
-	Token          string  `json:"token"`
-	ChainType      string  `json:"chain_type"`
-	ChainName      string  `json:"chain_name"`
-	ExpirationTime int64   `json:"expiration_time"`
-	PaymentURL     string  `json:"payment_url"`
+	TradeID        string    `json:"trade_id"`
+	OrderID        string    `json:"order_id"`
+	Amount         float64   `json:"amount"`
+	ActualAmount   float64   `json:"actual_amount"`
+	Token          string    `json:"token"`
+	ChainType      ChainType `json:"chain_type"`
+	ChainName      string    `json:"chain_name"`
+	ExpirationTime int64     `json:"expiration_time"`
+	PaymentURL     string    `json:"payment_url"`
+
+	// ExchangeRate is the crypto amount per fiat unit used for the order,
+	// and RateTimestamp the unix time it was quoted. Both are zero when the
+	// gateway does not report them.
+	ExchangeRate  float64 `json:"exchange_rate,omitempty"`
+	RateTimestamp int64   `json:"rate_timestamp,omitempty"`
+
+	// ReceiptStatus reports receipt delivery when CustomerEmail was set
+	ReceiptStatus string `json:"receipt_status,omitempty"`
+
+	// ConfirmationWindowMin and ConfirmationWindowMax are the expected
+	// confirmation time in seconds for the requested Priority. Both are
+	// zero when the gateway does not report them.
+	ConfirmationWindowMin int64 `json:"confirmation_window_min,omitempty"`
+	ConfirmationWindowMax int64 `json:"confirmation_window_max,omitempty"`
 }
 
 // PaymentResponse is the API response for payment operations
@@ -135,20 +477,36 @@ type PaymentResponse struct {
 	Message    string       `json:"message"`
 	Data       *PaymentData `json:"data"`
 	RequestID  string       `json:"request_id"`
+
+	// GeneratedOrderID is the order id filled in by WithAutoOrderID, if any
+	GeneratedOrderID string `json:"-"`
+
+	ResponseMeta
 }
 
 // OrderData holds order query data
 type OrderData struct {
-	TradeID            string  `json:"trade_id"`
-	OrderID            string  `json:"order_id"`
-	Amount             float64 `json:"amount"`
-	ActualAmount       float64 `json:"actual_amount"`
-	Token              string  `json:"token"`
-	ChainType          string  `json:"chain_type"`
-	Status             int     `json:"status"`
-	BlockTransactionID string  `json:"block_transaction_id"`
-	CreatedAt          string  `json:"created_at"`
-	PaidAt             string  `json:"paid_at"`
+	TradeID            string        `json:"trade_id"`
+	OrderID            string        `json:"order_id"`
+	Amount             float64       `json:"amount"`
+	ActualAmount       float64       `json:"actual_amount"`
+	Token              string        `json:"token"`
+	ChainType          ChainType     `json:"chain_type"`
+	Status             PaymentStatus `json:"status"`
+	BlockTransactionID string        `json:"block_transaction_id"`
+	CreatedAt          string        `json:"created_at"`
+	PaidAt             string        `json:"paid_at"`
+
+	// ExchangeRate and RateTimestamp mirror the fields on PaymentData
+	ExchangeRate  float64 `json:"exchange_rate,omitempty"`
+	RateTimestamp int64   `json:"rate_timestamp,omitempty"`
+
+	// ExpirationTime is the unix time a pending order expires, when reported
+	ExpirationTime int64 `json:"expiration_time,omitempty"`
+
+	// AmountReceived is the crypto amount that has arrived, when reported.
+	// An underpaid order stays StatusPending, see IsPartiallyPaid.
+	AmountReceived float64 `json:"amount_received,omitempty"`
 }
 
 // OrderResponse is the API response for order queries
@@ -157,6 +515,8 @@ type OrderResponse struct {
 	Message    string     `json:"message"`
 	Data       *OrderData `json:"data"`
 	RequestID  string     `json:"request_id"`
+
+	ResponseMeta
 }
 
 // OrderListData holds paginated order list data
@@ -165,6 +525,10 @@ type OrderListData struct {
 	Total    int         `json:"total"`
 	Page     int         `json:"page"`
 	PageSize int         `json:"page_size"`
+
+	// NextCursor continues the listing with ListOrdersCursor. It is empty
+	// on the last page and when the gateway does not support cursors.
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // OrderListResponse is the API response for order list
@@ -173,31 +537,85 @@ type OrderListResponse struct {
 	Message    string         `json:"message"`
 	Data       *OrderListData `json:"data"`
 	RequestID  string         `json:"request_id"`
+
+	ResponseMeta
 }
 
 // ListOrdersParams holds parameters for listing orders
 type ListOrdersParams struct {
-	Page      int    `json:"page,omitempty"`
-	PageSize  int    `json:"page_size,omitempty"`
-	Status    int    `json:"status,omitempty"`
-	ChainType string `json:"chain_type,omitempty"`
-	StartDate string `json:"start_date,omitempty"`
-	EndDate   string `json:"end_date,omitempty"`
+	Page      int           `json:"page,omitempty"`
+	PageSize  int           `json:"page_size,omitempty"`
+	Status    PaymentStatus `json:"status,omitempty"`
+	ChainType ChainType     `json:"chain_type,omitempty"`
+	StartDate string        `json:"start_date,omitempty"`
+	EndDate   string        `json:"end_date,omitempty"`
+
+	// StartDate and EndDate are UTC calendar dates in DateLayout, both
+	// inclusive, since the gateway filters by whole UTC days. StartTime and
+	// EndTime take precedence over them and are sent as their UTC date:
+	// 2025-12-01T23:30:00-05:00 is the UTC day 2025-12-02.
+	StartTime time.Time `json:"-"`
+	EndTime   time.Time `json:"-"`
+
+	// Fields limits the returned order fields, e.g. []string{"trade_id", "status"}.
+	// Names must be OrderData JSON field names. Fields the server omits keep
+	// their zero value; a server without field selection returns every field.
+	Fields []string `json:"fields,omitempty"`
+
+	// SortBy orders the results by one of SortByCreatedAt, SortByPaidAt or
+	// SortByAmount, and SortOrder is SortAsc or SortDesc. Both are
+	// optional; the gateway's default order is used when SortBy is empty.
+	SortBy    string `json:"sort_by,omitempty"`
+	SortOrder string `json:"sort_order,omitempty"`
+
+	// MinAmount and MaxAmount bound the order amount, inclusive; zero
+	// leaves that side open. OrderIDPrefix matches order ids starting
+	// with it, e.g. "SUBSCRIPTION_".
+	MinAmount     float64 `json:"min_amount,omitempty"`
+	MaxAmount     float64 `json:"max_amount,omitempty"`
+	OrderIDPrefix string  `json:"order_id_prefix,omitempty"`
+
+	// cursor is set by ListOrdersCursor
+	cursor string
 }
 
+// DateLayout is the layout of ListOrdersParams.StartDate and EndDate
+const DateLayout = "2006-01-02"
+
+// ListOrders sort fields and directions
+const (
+	SortByCreatedAt = "created_at"
+	SortByPaidAt    = "paid_at"
+	SortByAmount    = "amount"
+
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
 // WebhookPayload represents a webhook callback payload
 type WebhookPayload struct {
-	TradeID            string  `json:"trade_id"`
-	OrderID            string  `json:"order_id"`
-	Amount             float64 `json:"amount"`
-	ActualAmount       float64 `json:"actual_amount"`
-	Token              string  `json:"token"`
-	ChainType          string  `json:"chain_type"`
-	ChainName          string  `json:"chain_name"`
-	BlockTransactionID string  `json:"block_transaction_id"`
-	Status             int     `json:"status"`
-	Timestamp          int64   `json:"timestamp"`
-	Signature          string  `json:"signature"`
+	TradeID            string        `json:"trade_id"`
+	OrderID            string        `json:"order_id"`
+	Amount             float64       `json:"amount"`
+	ActualAmount       float64       `json:"actual_amount"`
+	Token              string        `json:"token"`
+	ChainType          ChainType     `json:"chain_type"`
+	ChainName          string        `json:"chain_name"`
+	BlockTransactionID string        `json:"block_transaction_id"`
+	Status             PaymentStatus `json:"status"`
+	Timestamp          int64         `json:"timestamp"`
+	Signature          string        `json:"signature"`
+
+	// SignType names the signature scheme when the gateway sends one
+	SignType string `json:"sign_type,omitempty"`
+
+	// EventType names the event when the gateway sends one; see
+	// WebhookEvent for deliveries without it
+	EventType WebhookEventType `json:"event_type,omitempty"`
+
+	// AmountReceived is the crypto amount that has arrived, when reported;
+	// see IsOverpaid and IsPartiallyPaid
+	AmountReceived float64 `json:"amount_received,omitempty"`
 }
 
 // MerchantData holds merchant profile data
@@ -210,6 +628,10 @@ type MerchantData struct {
 	KYCStatus    string `json:"kyc_status"`
 	KYCLevel     int    `json:"kyc_level"`
 	CreatedAt    string `json:"created_at"`
+
+	// MaxOrderAmount is the merchant's order limit when the server reports
+	// one; it overrides the KYCPolicy limit
+	MaxOrderAmount float64 `json:"max_order_amount,omitempty"`
 }
 
 // MerchantResponse is the API response for merchant operations
@@ -218,72 +640,360 @@ type MerchantResponse struct {
 	Message    string        `json:"message"`
 	Data       *MerchantData `json:"data"`
 	RequestID  string        `json:"request_id"`
+
+	ResponseMeta
 }
 
 // CreatePayment creates a new payment order
-func (c *Client) CreatePayment(params *CreatePaymentParams) (*PaymentResponse, error) {
-	timestamp := fmt.Sprintf("%d", time.Now().Unix())
-	nonce := generateNonce()
+func (c *Client) CreatePayment(params *CreatePaymentParams, opts ...RequestOption) (*PaymentResponse, error) {
+	return c.createPayment(context.Background(), params, opts...)
+}
+
+func (c *Client) createPayment(ctx context.Context, params *CreatePaymentParams, opts ...RequestOption) (*PaymentResponse, error) {
+	params, generatedOrderID, err := c.preparePayment(params)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkChainLimits(c.supportedChainsForCheck(ctx), params); err != nil {
+		return nil, err
+	}
+	creds := c.credentials()
+	body, _, err := c.paymentBody(creds, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// The key goes first so a WithIdempotencyKey in opts overrides it
+	opts = append([]RequestOption{WithIdempotencyKey(orderIdempotencyKey(params)), withAPIKey(creds.apiKey)}, opts...)
+
+	var resp PaymentResponse
+	err = c.request(ctx, "POST", "/order/create-transaction", body, &resp, opts...)
+	resp.GeneratedOrderID = generatedOrderID
+	if err == nil && c.validateResponses && resp.Data != nil {
+		err = ValidateWalletAddress(string(resp.Data.ChainType), resp.Data.Token)
+	}
+	return &resp, err
+}
+
+// paymentBody signs prepared params with creds and returns the request
+// body along with the signed parameters
+func (c *Client) paymentBody(creds credentials, params *CreatePaymentParams) (map[string]interface{}, map[string]string, error) {
+	timestamp := c.timestamp()
+	nonce, err := c.nonce()
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// Build params map for signing
 	paramsMap := map[string]string{
-		"api_key":    c.apiKey,
-		"timestamp":  timestamp,
-		"nonce":      nonce,
-		"order_id":   params.OrderID,
-		"amount":     formatAmount(params.Amount),
-		"notify_url": params.NotifyURL,
+		"api_key":   creds.apiKey,
+		"timestamp": timestamp,
+		"nonce":     nonce,
+	}
+	for k, v := range paymentFields(params) {
+		paramsMap[k] = v
 	}
 
-	if params.RedirectURL != "" {
-		paramsMap["redirect_url"] = params.RedirectURL
+	// Sign, adding sign_type for a custom Signer
+	signature, err := c.generateSignature(creds, paramsMap)
+	if err != nil {
+		return nil, nil, err
 	}
-	if params.ChainType != "" {
-		paramsMap["chain_type"] = params.ChainType
+
+	// Build request body; the amount is sent as a JSON number
+	body := make(map[string]interface{}, len(paramsMap)+1)
+	for k, v := range paramsMap {
+		body[k] = v
 	}
+	if params.AmountString != "" {
+		body["amount"] = json.Number(params.AmountString)
+	} else {
+		body["amount"] = params.Amount
+	}
+	body["signature"] = signature
+	return body, paramsMap, nil
+}
 
-	// Generate HMAC-SHA256 signature
-	signature := c.generateSignature(paramsMap)
+// orderIdempotencyKey returns the idempotency key for creating params
+func orderIdempotencyKey(params *CreatePaymentParams) string {
+	if params.IdempotencyKey != "" {
+		return params.IdempotencyKey
+	}
+	return "order-" + params.OrderID
+}
 
-	// Build request body
-	body := map[string]interface{}{
-		"api_key":    c.apiKey,
-		"timestamp":  timestamp,
-		"nonce":      nonce,
+// preparePayment fills a generated order id when configured and validates
+// params. The caller's params are never modified.
+func (c *Client) preparePayment(params *CreatePaymentParams) (*CreatePaymentParams, string, error) {
+	var generatedOrderID string
+	if params.OrderID == "" && c.autoOrderID != nil {
+		generatedOrderID = c.autoOrderID()
+		if len(generatedOrderID) == 0 || len(generatedOrderID) > 64 {
+			return nil, "", &ValidationError{Field: "order_id", Message: "generated order id must be 1-64 characters"}
+		}
+		withID := *params
+		withID.OrderID = generatedOrderID
+		params = &withID
+	}
+
+	if err := params.validate(c.checkChainType); err != nil {
+		return nil, "", err
+	}
+	if params.AmountString != "" {
+		withAmount := *params
+		withAmount.AmountString, _ = normalizeAmount(params.AmountString, 2)
+		params = &withAmount
+	}
+
+	if err := c.checkCallbackURLs(params); err != nil {
+		return nil, "", err
+	}
+	return params, generatedOrderID, nil
+}
+
+// Validate checks params before they are sent: OrderID must be 1-64
+// characters, the amount positive, NotifyURL (and RedirectURL if set) an
+// absolute http(s) URL and ChainType, if set, one of the Chain* constants.
+// The first problem is returned as a *ValidationError naming the field.
+//
+// CreatePayment runs the same checks; use WithChainTypeCheck(false) to
+// allow chains newer than the SDK.
+func (p *CreatePaymentParams) Validate() error {
+	return p.validate(true)
+}
+
+func (p *CreatePaymentParams) validate(checkChain bool) error {
+	if n := len(p.OrderID); n == 0 || n > 64 {
+		return &ValidationError{Field: "order_id", Message: "must be 1-64 characters"}
+	}
+
+	if p.AmountString != "" {
+		amount, err := normalizeAmount(p.AmountString, 2)
+		if err != nil {
+			return &ValidationError{Field: "amount", Message: err.Error()}
+		}
+		if p.Amount != 0 && formatAmount(p.Amount) != amount {
+			return &ValidationError{Field: "amount", Message: fmt.Sprintf("Amount %s and AmountString %s disagree", formatAmount(p.Amount), amount)}
+		}
+		if strings.Trim(amount, "0.") == "" {
+			return &ValidationError{Field: "amount", Message: "must be greater than 0"}
+		}
+	} else if !(p.Amount > 0) {
+		return &ValidationError{Field: "amount", Message: "must be greater than 0"}
+	}
+
+	if err := validateHTTPURL("notify_url", p.NotifyURL); err != nil {
+		return err
+	}
+	if p.RedirectURL != "" {
+		if err := validateHTTPURL("redirect_url", p.RedirectURL); err != nil {
+			return err
+		}
+	}
+
+	if checkChain && p.ChainType != "" && !p.ChainType.Valid() {
+		return &ValidationError{Field: "chain_type", Message: fmt.Sprintf("unknown chain %q", p.ChainType)}
+	}
+
+	if p.CustomerEmail != "" && !isValidEmail(p.CustomerEmail) {
+		return &ValidationError{Field: "customer_email", Message: "invalid email address"}
+	}
+	switch p.Priority {
+	case "", PriorityEconomy, PriorityStandard, PriorityFast:
+	default:
+		return &ValidationError{Field: "priority", Message: fmt.Sprintf("unknown priority %q", p.Priority)}
+	}
+	return nil
+}
+
+// paymentFields returns the signed order fields of params
+func paymentFields(params *CreatePaymentParams) map[string]string {
+	fields := map[string]string{
 		"order_id":   params.OrderID,
-		"amount":     params.Amount,
+		"amount":     formatAmount(params.Amount),
 		"notify_url": params.NotifyURL,
-		"signature":  signature,
+	}
+
+	if params.AmountString != "" {
+		fields["amount"] = params.AmountString
 	}
 
 	if params.RedirectURL != "" {
-		body["redirect_url"] = params.RedirectURL
+		fields["redirect_url"] = params.RedirectURL
 	}
 	if params.ChainType != "" {
-		body["chain_type"] = params.ChainType
+		fields["chain_type"] = string(params.ChainType)
 	}
-
-	var resp PaymentResponse
-	err := c.request("POST", "/order/create-transaction", body, &resp)
-	return &resp, err
+	if params.CustomerEmail != "" {
+		fields["customer_email"] = params.CustomerEmail
+	}
+	if params.Priority != "" {
+		fields["priority"] = params.Priority
+	}
+	return fields
 }
 
 // QueryPaymentByTradeID queries a payment by trade_id
-func (c *Client) QueryPaymentByTradeID(tradeID string) (*OrderResponse, error) {
-	var resp OrderResponse
-	err := c.request("GET", "/merchant/order/query?trade_id="+url.QueryEscape(tradeID), nil, &resp)
-	return &resp, err
+func (c *Client) QueryPaymentByTradeID(tradeID string, opts ...RequestOption) (*OrderResponse, error) {
+	return c.queryOrder(context.Background(), "trade_id", tradeID, opts...)
 }
 
 // QueryPaymentByOrderID queries a payment by order_id
-func (c *Client) QueryPaymentByOrderID(orderID string) (*OrderResponse, error) {
+func (c *Client) QueryPaymentByOrderID(orderID string, opts ...RequestOption) (*OrderResponse, error) {
+	return c.queryOrder(context.Background(), "order_id", orderID, opts...)
+}
+
+// QueryParams identifies an order by trade_id, order_id or both
+type QueryParams struct {
+	OrderID string
+	TradeID string
+}
+
+// QueryPayment queries a payment by trade_id or order_id.
+// TradeID is preferred when both are set.
+func (c *Client) QueryPayment(ctx context.Context, params QueryParams, opts ...RequestOption) (*OrderResponse, error) {
+	switch {
+	case params.TradeID != "":
+		return c.queryOrder(ctx, "trade_id", params.TradeID, opts...)
+	case params.OrderID != "":
+		return c.queryOrder(ctx, "order_id", params.OrderID, opts...)
+	default:
+		return nil, &ValidationError{Field: "trade_id", Message: "trade_id or order_id is required"}
+	}
+}
+
+// queryOrder queries a single order by the given id field. A successful
+// response without order data is reported as ErrOrderNotFound so callers
+// never receive a nil Data on success.
+func (c *Client) queryOrder(ctx context.Context, field, id string, opts ...RequestOption) (*OrderResponse, error) {
 	var resp OrderResponse
-	err := c.request("GET", "/merchant/order/query?order_id="+url.QueryEscape(orderID), nil, &resp)
+	err := c.request(ctx, "GET", "/merchant/order/query?"+field+"="+url.QueryEscape(id), nil, &resp, opts...)
+	if err == nil && resp.StatusCode == 200 && resp.Data == nil {
+		err = ErrOrderNotFound
+	}
 	return &resp, err
 }
 
-// ListOrders lists orders with optional filters
-func (c *Client) ListOrders(params *ListOrdersParams) (*OrderListResponse, error) {
+// ListOrders lists orders with optional filters.
+//
+// A PageSize above the client's max page size (see WithMaxPageSize) is
+// served by fetching the covering server pages and merging them, so Page and
+// PageSize keep their meaning regardless of what the server accepts. Such a
+// PageSize may be at most MaxSplitPageSize.
+func (c *Client) ListOrders(params *ListOrdersParams, opts ...RequestOption) (*OrderListResponse, error) {
+	return c.listOrdersPage(context.Background(), params, opts...)
+}
+
+// listOrdersPage validates params and fetches one logical page
+func (c *Client) listOrdersPage(ctx context.Context, params *ListOrdersParams, opts ...RequestOption) (*OrderListResponse, error) {
+	if params.PageSize < 0 {
+		return nil, &ValidationError{Field: "page_size", Message: "must not be negative"}
+	}
+	if err := validateListFilters(params); err != nil {
+		return nil, err
+	}
+	if params.PageSize > c.maxPageSize {
+		if params.PageSize > MaxSplitPageSize {
+			return nil, &ValidationError{Field: "page_size", Message: fmt.Sprintf("must not exceed %d", MaxSplitPageSize)}
+		}
+		if params.Page > math.MaxInt/params.PageSize {
+			return nil, &ValidationError{Field: "page", Message: "is too large for page_size"}
+		}
+		c.logger.Log(ctx, LevelWarn, "cryptomepay: page size above max; splitting into several requests",
+			"page_size", params.PageSize, "max_page_size", c.maxPageSize)
+		return c.listOrdersSplit(ctx, params, opts...)
+	}
+	return c.listOrders(ctx, params, opts...)
+}
+
+// startDate returns the start_date to send, preferring StartTime
+func (p *ListOrdersParams) startDate() string {
+	if !p.StartTime.IsZero() {
+		return p.StartTime.UTC().Format(DateLayout)
+	}
+	return p.StartDate
+}
+
+// endDate returns the end_date to send, preferring EndTime
+func (p *ListOrdersParams) endDate() string {
+	if !p.EndTime.IsZero() {
+		return p.EndTime.UTC().Format(DateLayout)
+	}
+	return p.EndDate
+}
+
+// validateListFilters checks the field selection, sort, time range and
+// amount range of params
+func validateListFilters(params *ListOrdersParams) error {
+	for _, field := range params.Fields {
+		if !orderFieldNames[field] {
+			return &ValidationError{Field: "fields", Message: fmt.Sprintf("unknown order field %q", field)}
+		}
+	}
+	switch params.SortBy {
+	case "", SortByCreatedAt, SortByPaidAt, SortByAmount:
+	default:
+		return &ValidationError{Field: "sort_by", Message: fmt.Sprintf("unknown sort field %q", params.SortBy)}
+	}
+	switch params.SortOrder {
+	case "", SortAsc, SortDesc:
+	default:
+		return &ValidationError{Field: "sort_order", Message: fmt.Sprintf("must be %q or %q", SortAsc, SortDesc)}
+	}
+	if params.SortOrder != "" && params.SortBy == "" {
+		return &ValidationError{Field: "sort_order", Message: "requires sort_by"}
+	}
+	if !params.StartTime.IsZero() && !params.EndTime.IsZero() && params.StartTime.After(params.EndTime) {
+		return &ValidationError{Field: "start_time", Message: "must not be after end_time"}
+	}
+	if params.MinAmount < 0 {
+		return &ValidationError{Field: "min_amount", Message: "must not be negative"}
+	}
+	if params.MaxAmount < 0 {
+		return &ValidationError{Field: "max_amount", Message: "must not be negative"}
+	}
+	if params.MaxAmount > 0 && params.MinAmount > params.MaxAmount {
+		return &ValidationError{Field: "min_amount", Message: "must not exceed max_amount"}
+	}
+	return nil
+}
+
+// ListOrdersCursor fetches the page of orders following cursor, an
+// OrderListData.NextCursor from an earlier call; pass "" for the first
+// page. Page is ignored. Unlike page offsets, a cursor neither skips nor
+// repeats orders created during the scan. A gateway without cursor support
+// returns the first page and no NextCursor.
+func (c *Client) ListOrdersCursor(ctx context.Context, params *ListOrdersParams, cursor string, opts ...RequestOption) (*OrderListResponse, error) {
+	if params.PageSize < 0 || params.PageSize > c.maxPageSize {
+		return nil, &ValidationError{Field: "page_size", Message: fmt.Sprintf("must be between 0 and %d", c.maxPageSize)}
+	}
+	if err := validateListFilters(params); err != nil {
+		return nil, err
+	}
+
+	page := *params
+	page.Page = 0
+	page.cursor = cursor
+	return c.listOrders(ctx, &page, opts...)
+}
+
+// orderFieldNames holds the JSON names of the OrderData fields
+var orderFieldNames = jsonFieldNames(reflect.TypeOf(OrderData{}))
+
+// jsonFieldNames returns the set of JSON field names of struct type t
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// listOrders fetches a single page from the API
+func (c *Client) listOrders(ctx context.Context, params *ListOrdersParams, opts ...RequestOption) (*OrderListResponse, error) {
 	query := url.Values{}
 
 	if params.Page > 0 {
@@ -296,13 +1006,34 @@ func (c *Client) ListOrders(params *ListOrdersParams) (*OrderListResponse, error
 		query.Set("status", fmt.Sprintf("%d", params.Status))
 	}
 	if params.ChainType != "" {
-		query.Set("chain_type", params.ChainType)
+		query.Set("chain_type", string(params.ChainType))
+	}
+	if date := params.startDate(); date != "" {
+		query.Set("start_date", date)
+	}
+	if date := params.endDate(); date != "" {
+		query.Set("end_date", date)
+	}
+	if len(params.Fields) > 0 {
+		query.Set("fields", strings.Join(params.Fields, ","))
+	}
+	if params.SortBy != "" {
+		query.Set("sort_by", params.SortBy)
+	}
+	if params.SortOrder != "" {
+		query.Set("sort_order", params.SortOrder)
+	}
+	if params.MinAmount > 0 {
+		query.Set("min_amount", strconv.FormatFloat(params.MinAmount, 'f', -1, 64))
+	}
+	if params.MaxAmount > 0 {
+		query.Set("max_amount", strconv.FormatFloat(params.MaxAmount, 'f', -1, 64))
 	}
-	if params.StartDate != "" {
-		query.Set("start_date", params.StartDate)
+	if params.OrderIDPrefix != "" {
+		query.Set("order_id_prefix", params.OrderIDPrefix)
 	}
-	if params.EndDate != "" {
-		query.Set("end_date", params.EndDate)
+	if params.cursor != "" {
+		query.Set("cursor", params.cursor)
 	}
 
 	endpoint := "/merchant/orders"
@@ -311,40 +1042,110 @@ func (c *Client) ListOrders(params *ListOrdersParams) (*OrderListResponse, error
 	}
 
 	var resp OrderListResponse
-	err := c.request("GET", endpoint, nil, &resp)
+	err := c.request(ctx, "GET", endpoint, nil, &resp, opts...)
 	return &resp, err
 }
 
-// GetMerchantInfo gets the merchant profile
-func (c *Client) GetMerchantInfo() (*MerchantResponse, error) {
-	var resp MerchantResponse
-	err := c.request("GET", "/merchant/info", nil, &resp)
-	return &resp, err
+// listOrdersSplit serves an oversized logical page from max-sized server pages
+func (c *Client) listOrdersSplit(ctx context.Context, params *ListOrdersParams, opts ...RequestOption) (*OrderListResponse, error) {
+	size := params.PageSize
+	page := params.Page
+	if page < 1 {
+		page = 1
+	}
+
+	start := (page - 1) * size
+	end := start + size
+	serverSize := c.maxPageSize
+
+	// The list grows with the orders that arrive rather than with size
+	data := &OrderListData{List: []OrderData{}, Page: page, PageSize: size}
+	var last *OrderListResponse
+
+	for serverPage := start/serverSize + 1; (serverPage-1)*serverSize < end; serverPage++ {
+		sub := *params
+		sub.Page = serverPage
+		sub.PageSize = serverSize
+
+		resp, err := c.listOrders(ctx, &sub, opts...)
+		if err != nil || resp.StatusCode != 200 || resp.Data == nil {
+			return resp, err
+		}
+		last = resp
+
+		offset := (serverPage - 1) * serverSize
+		for i, order := range resp.Data.List {
+			if idx := offset + i; idx >= start && idx < end {
+				data.List = append(data.List, order)
+			}
+		}
+
+		data.Total = resp.Data.Total
+		if len(resp.Data.List) < serverSize || offset+serverSize >= resp.Data.Total {
+			break
+		}
+	}
+
+	return &OrderListResponse{
+		StatusCode:   last.StatusCode,
+		Message:      last.Message,
+		Data:         data,
+		RequestID:    last.RequestID,
+		ResponseMeta: last.ResponseMeta,
+	}, nil
 }
 
-// VerifyWebhookSignature verifies a webhook payload signature (HMAC-SHA256)
+// VerifyWebhookSignature verifies a webhook payload signature (HMAC-SHA256,
+// or the scheme set with WithSigner).
+//
+// Only the fields present in the payload are signed, so minimal lifecycle
+// pings carrying just trade_id, status and signature verify as well.
 func (c *Client) VerifyWebhookSignature(payload *WebhookPayload) bool {
+	ok := c.verifyWebhookSignature(payload)
+	c.webhookVerified(payload, ok)
+	return ok
+}
+
+func (c *Client) verifyWebhookSignature(payload *WebhookPayload) bool {
 	params := map[string]string{
 		"trade_id":             payload.TradeID,
 		"order_id":             payload.OrderID,
-		"amount":               formatAmount(payload.Amount),
-		"actual_amount":        formatActualAmount(payload.ActualAmount),
 		"token":                payload.Token,
-		"chain_type":           payload.ChainType,
+		"chain_type":           string(payload.ChainType),
+		"chain_name":           payload.ChainName,
 		"block_transaction_id": payload.BlockTransactionID,
 		"status":               fmt.Sprintf("%d", payload.Status),
-		"timestamp":            fmt.Sprintf("%d", payload.Timestamp),
+		"sign_type":            payload.SignType,
+		"event_type":           string(payload.EventType),
 	}
 
-	if payload.ChainName != "" {
-		params["chain_name"] = payload.ChainName
+	// Zero values mean the field was not delivered and must not be signed
+	amounts := make(map[string][]string)
+	if payload.Amount != 0 {
+		amounts["amount"] = amountVariants(payload.Amount, 2)
+	}
+	if payload.ActualAmount != 0 {
+		amounts["actual_amount"] = amountVariants(payload.ActualAmount, 4)
+	}
+	if payload.AmountReceived != 0 {
+		amounts["amount_received"] = amountVariants(payload.AmountReceived, 4)
+	}
+	if payload.Timestamp != 0 {
+		params["timestamp"] = fmt.Sprintf("%d", payload.Timestamp)
 	}
 
-	expected := c.calculateSignature(params)
-	return subtle.ConstantTimeCompare([]byte(expected), []byte(payload.Signature)) == 1
+	return c.verifyAmountVariants(params, amounts, payload.Signature)
 }
 
-// VerifyWebhookSignatureFromMap verifies a webhook signature from a map (HMAC-SHA256)
+// VerifyWebhookSignatureFromMap verifies a webhook signature from a map
+// (HMAC-SHA256, or the scheme set with WithSigner), such as a JSON body
+// decoded into map[string]interface{} with or without json.Decoder.UseNumber.
+//
+// amount is tried with 2 decimals, actual_amount and amount_received with
+// 4, as the gateway signs them, and also as written. Whole numbers such as
+// timestamps are signed without a fraction or exponent, booleans as "true"
+// or "false", and nested objects and arrays as compact JSON with sorted
+// keys.
 func (c *Client) VerifyWebhookSignatureFromMap(payload map[string]interface{}) bool {
 	signature, ok := payload["signature"].(string)
 	if !ok {
@@ -352,6 +1153,7 @@ func (c *Client) VerifyWebhookSignatureFromMap(payload map[string]interface{}) b
 	}
 
 	params := make(map[string]string)
+	amounts := make(map[string][]string)
 	for k, v := range payload {
 		if k == "signature" {
 			continue
@@ -359,37 +1161,35 @@ func (c *Client) VerifyWebhookSignatureFromMap(payload map[string]interface{}) b
 		if v == nil || v == "" {
 			continue
 		}
-		// Format numbers correctly
-		switch val := v.(type) {
-		case float64:
-			if k == "amount" {
-				params[k] = fmt.Sprintf("%.2f", val)
-			} else if k == "actual_amount" {
-				params[k] = fmt.Sprintf("%.4f", val)
-			} else {
-				params[k] = fmt.Sprintf("%v", v)
+		if decimals, ok := amountDecimals[k]; ok {
+			if variants := signedAmountVariants(v, decimals); variants != nil {
+				amounts[k] = variants
+				continue
 			}
-		default:
-			params[k] = fmt.Sprintf("%v", v)
 		}
+		value, ok := formatSignedValue(v)
+		if !ok {
+			return false
+		}
+		params[k] = value
 	}
 
-	expected := c.calculateSignature(params)
-	return subtle.ConstantTimeCompare([]byte(expected), []byte(signature)) == 1
+	return c.verifyAmountVariants(params, amounts, signature)
 }
 
-// calculateSignature calculates HMAC-SHA256 signature
-func (c *Client) calculateSignature(params map[string]string) string {
-	// Get sorted keys (excluding empty values and signature field)
-	keys := make([]string, 0, len(params))
-	for k, v := range params {
-		if k != "signature" && v != "" {
-			keys = append(keys, k)
-		}
-	}
-	sort.Strings(keys)
+// SigningString builds the canonical string that is signed with HMAC-SHA256.
+//
+// Keys are sorted, empty values and the signature field are skipped, and the
+// remaining pairs are joined as key=value with "&".
+//
+// Values are taken verbatim as UTF-8, without percent-encoding or Unicode
+// normalization. The gateway rebuilds the string from the decoded request
+// parameters rather than by splitting it, so a value containing "&" or "=",
+// such as a notify_url with a query string, signs as is and must not be
+// escaped by the caller.
+func SigningString(params map[string]string) string {
+	keys := signedKeys(params)
 
-	// Build query string
 	var builder strings.Builder
 	for i, k := range keys {
 		if i > 0 {
@@ -399,15 +1199,11 @@ func (c *Client) calculateSignature(params map[string]string) string {
 		builder.WriteString("=")
 		builder.WriteString(params[k])
 	}
-
-	h := hmac.New(sha256.New, []byte(c.apiSecret))
-	h.Write([]byte(builder.String()))
-	return hex.EncodeToString(h.Sum(nil))
+	return builder.String()
 }
 
-// generateSignature generates HMAC-SHA256 signature
-func (c *Client) generateSignature(params map[string]string) string {
-	// Get sorted keys (excluding empty values and signature)
+// signedKeys returns the sorted parameter names covered by a signature
+func signedKeys(params map[string]string) []string {
 	keys := make([]string, 0, len(params))
 	for k, v := range params {
 		if k != "signature" && v != "" {
@@ -415,71 +1211,102 @@ func (c *Client) generateSignature(params map[string]string) string {
 		}
 	}
 	sort.Strings(keys)
+	return keys
+}
 
-	// Build query string
-	var builder strings.Builder
-	for i, k := range keys {
-		if i > 0 {
-			builder.WriteString("&")
-		}
-		builder.WriteString(k)
-		builder.WriteString("=")
-		builder.WriteString(params[k])
+// calculateSignature returns the expected signature of params with the
+// client's Signer, or "" if it fails, which matches no signature
+func (c *Client) calculateSignature(params map[string]string) string {
+	signature, err := c.requestSigner(c.credentials().apiSecret).Sign(params)
+	if err != nil {
+		return ""
 	}
+	return signature
+}
 
-	// Calculate HMAC-SHA256
-	h := hmac.New(sha256.New, []byte(c.apiSecret))
-	h.Write([]byte(builder.String()))
-	return hex.EncodeToString(h.Sum(nil))
+// generateSignature signs outgoing request params with the client's Signer,
+// or HMAC-SHA256 over creds' secret, first adding the signer's sign_type to
+// params when it has one
+func (c *Client) generateSignature(creds credentials, params map[string]string) (string, error) {
+	signer := c.requestSigner(creds.apiSecret)
+	if signType := signer.SignType(); signType != "" {
+		params["sign_type"] = signType
+	}
+
+	signature, err := signer.Sign(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign request: %w", err)
+	}
+	c.logger.Log(context.Background(), LevelDebug, "cryptomepay: signed request",
+		"params", signedKeys(params), "signature", redactKey(signature))
+	return signature, nil
 }
 
-// generateNonce generates a random nonce string
-func generateNonce() string {
-	b := make([]byte, 16)
-	rand.Read(b)
-	return hex.EncodeToString(b)
+// signHMAC returns the hex encoded HMAC-SHA256 of data keyed by secret
+func signHMAC(secret, data string) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(data))
+	return hex.EncodeToString(h.Sum(nil))
 }
 
-// request makes an HTTP request
-func (c *Client) request(method, endpoint string, body interface{}, result interface{}) error {
-	var reqBody io.Reader
+// hmacEqual compares two signatures in constant time
+func hmacEqual(expected, actual string) bool {
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(actual)) == 1
+}
 
-	if body != nil {
-		jsonBody, err := json.Marshal(body)
-		if err != nil {
-			return fmt.Errorf("failed to marshal request body: %w", err)
-		}
-		reqBody = bytes.NewReader(jsonBody)
-	}
+// randReader is the entropy source for nonces, replaced in tests
+var randReader io.Reader = rand.Reader
 
-	req, err := http.NewRequest(method, c.baseURL+endpoint, reqBody)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+// generateNonce generates a random nonce string. It fails rather than
+// return a predictable nonce when the entropy source does.
+func generateNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := io.ReadFull(randReader, b); err != nil {
+		return "", fmt.Errorf("cryptomepay: failed to generate nonce: %w", err)
 	}
+	return hex.EncodeToString(b), nil
+}
 
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("User-Agent", "cryptomepay-go/"+Version)
+// orderIDCounter keeps fallback DefaultOrderID suffixes distinct
+var orderIDCounter uint32
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+// nonce returns the nonce for one signed request
+func (c *Client) nonce() (string, error) {
+	if c.nonceSource != nil {
+		return c.nonceSource(), nil
 	}
-	defer resp.Body.Close()
+	return generateNonce()
+}
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
+// checkCallbackURLs enforces HTTPS callback URLs when required
+func (c *Client) checkCallbackURLs(params *CreatePaymentParams) error {
+	require := c.baseURL == ProductionURL
+	if c.requireHTTPSNotify != nil {
+		require = *c.requireHTTPSNotify
 	}
-
-	if err := json.Unmarshal(respBody, result); err != nil {
-		return fmt.Errorf("failed to unmarshal response: %w", err)
+	if !require {
+		return nil
 	}
 
+	if !strings.HasPrefix(strings.ToLower(params.NotifyURL), "https://") {
+		return &ValidationError{Field: "notify_url", Message: "must use https in production"}
+	}
+	if params.RedirectURL != "" && !strings.HasPrefix(strings.ToLower(params.RedirectURL), "https://") {
+		return &ValidationError{Field: "redirect_url", Message: "must use https in production"}
+	}
 	return nil
 }
 
+// isValidEmail reports whether s is a bare email address such as user@example.com
+func isValidEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s {
+		return false
+	}
+	domain := s[strings.LastIndex(s, "@")+1:]
+	return strings.Contains(domain, ".")
+}
+
 func formatAmount(amount float64) string {
 	return fmt.Sprintf("%.2f", amount)
 }
@@ -1,10 +1,21 @@
 package cryptomepay
 
 import (
+	"context"
+	"crypto/rand"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -39,14 +50,18 @@ func TestGenerateSignature(t *testing.T) {
 		"chain_type": "BSC",
 	}
 
-	signature := client.generateSignature(params)
+	signature, err := client.generateSignature(client.credentials(), params)
+	assert.NoError(t, err)
 
 	// Signature should be 64 character hex string (HMAC-SHA256)
 	assert.Len(t, signature, 64)
 
 	// Same params should produce same signature
-	signature2 := client.generateSignature(params)
+	signature2, _ := client.generateSignature(client.credentials(), params)
 	assert.Equal(t, signature, signature2)
+
+	// The default signer sends no sign_type
+	assert.NotContains(t, params, "sign_type")
 }
 
 func TestGenerateSignatureOrder(t *testing.T) {
@@ -65,7 +80,84 @@ func TestGenerateSignatureOrder(t *testing.T) {
 		"amount":     "100.00",
 	}
 
-	assert.Equal(t, client.generateSignature(params1), client.generateSignature(params2))
+	assert.Equal(t, client.calculateSignature(params1), client.calculateSignature(params2))
+}
+
+func TestSigningStringVerbatimValues(t *testing.T) {
+	client := NewClient("sk_test_key", "test_secret")
+
+	// Expected signatures are computed independently with HMAC-SHA256 over
+	// the sorted key=value pairs, values unescaped
+	tests := []struct {
+		name      string
+		params    map[string]string
+		signing   string
+		signature string
+	}{
+		{
+			name: "notify_url with query string",
+			params: map[string]string{
+				"order_id": "ORDER_001", "amount": "100.00", "notify_url": "https://x.com/cb?a=1&b=2",
+				"api_key": "sk_test_key", "timestamp": "1700000000", "nonce": "abc123",
+			},
+			signing:   "amount=100.00&api_key=sk_test_key&nonce=abc123&notify_url=https://x.com/cb?a=1&b=2&order_id=ORDER_001&timestamp=1700000000",
+			signature: "369870fdb5ed05bf5afcfc15f48594b968fae50c9a46104361aaa8b0663f4bfa",
+		},
+		{
+			name: "unicode order_id",
+			params: map[string]string{
+				"order_id": "订单-001-ü", "amount": "100.00", "notify_url": "https://example.com/webhook",
+				"api_key": "sk_test_key", "timestamp": "1700000000", "nonce": "abc123",
+			},
+			signing:   "amount=100.00&api_key=sk_test_key&nonce=abc123&notify_url=https://example.com/webhook&order_id=订单-001-ü&timestamp=1700000000",
+			signature: "41926b851149d02079772436b7bc84ba9dd795c388305b5438dcbea713ae0952",
+		},
+		{
+			name: "bulk orders with a query string",
+			params: map[string]string{
+				"orders":  `[{"amount":"100.00","notify_url":"https://x.com/cb?a=1&b=2","order_id":"ORDER_001"}]`,
+				"api_key": "sk_test_key", "timestamp": "1700000000", "nonce": "abc123",
+			},
+			signing:   `api_key=sk_test_key&nonce=abc123&orders=[{"amount":"100.00","notify_url":"https://x.com/cb?a=1&b=2","order_id":"ORDER_001"}]&timestamp=1700000000`,
+			signature: "342ab7378e65fda4d484d7bf14f201f767ce9023041e1a954eb8951f52c8e228",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.signing, SigningString(tc.params))
+			assert.Equal(t, tc.signature, client.calculateSignature(tc.params))
+		})
+	}
+}
+
+func TestCreatePaymentSignatureRebuiltFromBody(t *testing.T) {
+	client := NewClient("sk_test_key", "test_secret")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Verify the way the gateway does: from the decoded body values
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		params := make(map[string]string, len(body))
+		for k, v := range body {
+			if k == "amount" {
+				v = formatAmount(v.(float64))
+			}
+			params[k] = v.(string)
+		}
+		assert.Equal(t, "https://x.com/cb?a=1&b=2", params["notify_url"])
+		assert.Equal(t, client.calculateSignature(params), params["signature"])
+
+		json.NewEncoder(w).Encode(PaymentResponse{StatusCode: 200, Data: &PaymentData{TradeID: "CP1"}})
+	}))
+	defer server.Close()
+	client = client.Clone(WithBaseURL(server.URL))
+
+	_, err := client.CreatePayment(&CreatePaymentParams{
+		OrderID:   "订单-001",
+		Amount:    100,
+		NotifyURL: "https://x.com/cb?a=1&b=2",
+	})
+	assert.NoError(t, err)
 }
 
 func TestCreatePayment(t *testing.T) {
@@ -156,6 +248,36 @@ func TestQueryPayment(t *testing.T) {
 	assert.Equal(t, StatusPaid, result.Data.Status)
 }
 
+func TestQueryPaymentParams(t *testing.T) {
+	var lastQuery url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastQuery = r.URL.Query()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(OrderResponse{StatusCode: 200, Data: &OrderData{TradeID: "CP1"}})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	// Neither id set
+	_, err := client.QueryPayment(context.Background(), QueryParams{})
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "trade_id", validationErr.Field)
+
+	// Both set prefers trade_id
+	_, err = client.QueryPayment(context.Background(), QueryParams{OrderID: "O1", TradeID: "CP1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "CP1", lastQuery.Get("trade_id"))
+	assert.Empty(t, lastQuery.Get("order_id"))
+
+	// Order id only
+	_, err = client.QueryPayment(context.Background(), QueryParams{OrderID: "O1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "O1", lastQuery.Get("order_id"))
+}
+
 func TestVerifyWebhookSignature(t *testing.T) {
 	client := NewClient("sk_test_key", "test_secret")
 
@@ -171,7 +293,7 @@ func TestVerifyWebhookSignature(t *testing.T) {
 		"status":               "2",
 		"timestamp":            "1700000000",
 	}
-	validSignature := client.generateSignature(params)
+	validSignature := client.calculateSignature(params)
 
 	// Test with valid signature
 	payload := &WebhookPayload{
@@ -240,7 +362,734 @@ func TestConstants(t *testing.T) {
 	assert.Equal(t, "ETH", ChainETH)
 	assert.Equal(t, "ARBITRUM", ChainArbitrum)
 
-	assert.Equal(t, 1, StatusPending)
-	assert.Equal(t, 2, StatusPaid)
-	assert.Equal(t, 3, StatusExpired)
+	assert.Equal(t, PaymentStatus(1), StatusPending)
+	assert.Equal(t, PaymentStatus(2), StatusPaid)
+	assert.Equal(t, PaymentStatus(3), StatusExpired)
+}
+
+// newOrdersServer serves total orders paginated by page and page_size
+func newOrdersServer(total int, calls *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+
+		list := []OrderData{}
+		for i := (page - 1) * size; i < page*size && i < total; i++ {
+			list = append(list, OrderData{TradeID: fmt.Sprintf("CP%d", i)})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(OrderListResponse{
+			StatusCode: 200,
+			Data:       &OrderListData{List: list, Total: total, Page: page, PageSize: size},
+		})
+	}))
+}
+
+func TestListOrdersMaxPageSize(t *testing.T) {
+	calls := 0
+	server := newOrdersServer(250, &calls)
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithMaxPageSize(100),
+	)
+
+	// At the limit a single request is sent
+	result, err := client.ListOrders(&ListOrdersParams{Page: 1, PageSize: 100})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Len(t, result.Data.List, 100)
+
+	// One over the limit splits into two server pages
+	calls = 0
+	result, err = client.ListOrders(&ListOrdersParams{Page: 1, PageSize: 101})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Len(t, result.Data.List, 101)
+	assert.Equal(t, "CP100", result.Data.List[100].TradeID)
+	assert.Equal(t, 101, result.Data.PageSize)
+
+	// Logical page 2 of 150 covers orders 150..249
+	calls = 0
+	result, err = client.ListOrders(&ListOrdersParams{Page: 2, PageSize: 150})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Len(t, result.Data.List, 100)
+	assert.Equal(t, "CP150", result.Data.List[0].TradeID)
+	assert.Equal(t, "CP249", result.Data.List[99].TradeID)
+	assert.Equal(t, 250, result.Data.Total)
+
+	_, err = client.ListOrders(&ListOrdersParams{PageSize: -1})
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+
+	// Sizes too large to split, or pages whose offset overflows, are
+	// rejected before any request
+	calls = 0
+	_, err = client.ListOrders(&ListOrdersParams{PageSize: 1 << 40})
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, "page_size", validationErr.Field)
+	}
+	_, err = client.ListOrders(&ListOrdersParams{Page: math.MaxInt / 100, PageSize: MaxSplitPageSize})
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, "page", validationErr.Field)
+	}
+	assert.Equal(t, 0, calls)
+
+	// Merged lists only hold the orders that arrived
+	result, err = client.ListOrders(&ListOrdersParams{Page: 1, PageSize: MaxSplitPageSize})
+	assert.NoError(t, err)
+	assert.Len(t, result.Data.List, 250)
+	assert.Equal(t, 3, calls)
+}
+
+func TestCreatePaymentCustomerEmail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		assert.Equal(t, "buyer@example.com", body["customer_email"])
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(PaymentResponse{
+			StatusCode: 200,
+			Data:       &PaymentData{TradeID: "CP1", ReceiptStatus: "queued"},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	payment, err := client.CreatePayment(&CreatePaymentParams{
+		OrderID:       "ORDER_001",
+		Amount:        100,
+		NotifyURL:     "https://example.com/webhook",
+		CustomerEmail: "buyer@example.com",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "queued", payment.Data.ReceiptStatus)
+
+	for _, email := range []string{"not-an-email", "Buyer <buyer@example.com>", "buyer@localhost"} {
+		_, err = client.CreatePayment(&CreatePaymentParams{
+			OrderID:       "ORDER_001",
+			Amount:        100,
+			NotifyURL:     "https://example.com/webhook",
+			CustomerEmail: email,
+		})
+		var validationErr *ValidationError
+		assert.ErrorAs(t, err, &validationErr, email)
+		assert.Equal(t, "customer_email", validationErr.Field)
+	}
+}
+
+func TestCreatePaymentPriority(t *testing.T) {
+	client := NewClient("sk_test_key", "test_secret")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		assert.Equal(t, PriorityFast, body["priority"])
+
+		// priority is part of the signed payload
+		signed := map[string]string{}
+		for k, v := range body {
+			if s, ok := v.(string); ok && k != "signature" {
+				signed[k] = s
+			}
+		}
+		signed["amount"] = "100.00"
+		assert.Equal(t, client.calculateSignature(signed), body["signature"])
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(PaymentResponse{
+			StatusCode: 200,
+			Data:       &PaymentData{TradeID: "CP1", ConfirmationWindowMin: 30, ConfirmationWindowMax: 120},
+		})
+	}))
+	defer server.Close()
+
+	client = NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	payment, err := client.CreatePayment(&CreatePaymentParams{
+		OrderID:   "ORDER_001",
+		Amount:    100,
+		NotifyURL: "https://example.com/webhook",
+		Priority:  PriorityFast,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(30), payment.Data.ConfirmationWindowMin)
+	assert.Equal(t, int64(120), payment.Data.ConfirmationWindowMax)
+
+	_, err = client.CreatePayment(&CreatePaymentParams{
+		OrderID:   "ORDER_001",
+		Amount:    100,
+		NotifyURL: "https://example.com/webhook",
+		Priority:  "urgent",
+	})
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "priority", validationErr.Field)
+}
+
+func TestCreatePaymentAmountString(t *testing.T) {
+	client := NewClient("sk_test_key", "test_secret")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		assert.Contains(t, string(raw), `"amount":19.90`)
+
+		var body map[string]interface{}
+		json.Unmarshal(raw, &body)
+		signed := map[string]string{"amount": "19.90"}
+		for k, v := range body {
+			if s, ok := v.(string); ok && k != "signature" {
+				signed[k] = s
+			}
+		}
+		assert.Equal(t, client.calculateSignature(signed), body["signature"])
+
+		json.NewEncoder(w).Encode(PaymentResponse{StatusCode: 200, Data: &PaymentData{TradeID: "CP1"}})
+	}))
+	defer server.Close()
+
+	client = NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	params := &CreatePaymentParams{OrderID: "ORDER_001", AmountString: "19.9", NotifyURL: "https://example.com/webhook"}
+	_, err := client.CreatePayment(params)
+	assert.NoError(t, err)
+	assert.Equal(t, "19.9", params.AmountString, "caller params are not modified")
+
+	for _, p := range []*CreatePaymentParams{
+		{OrderID: "ORDER_001", AmountString: "19.999", NotifyURL: "https://example.com/webhook"},
+		{OrderID: "ORDER_001", Amount: 20, AmountString: "19.99", NotifyURL: "https://example.com/webhook"},
+	} {
+		_, err := client.CreatePayment(p)
+		var validationErr *ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "amount", validationErr.Field)
+	}
+}
+
+func TestCreatePaymentParamsValidate(t *testing.T) {
+	valid := func() *CreatePaymentParams {
+		return &CreatePaymentParams{OrderID: "ORDER_001", Amount: 10, NotifyURL: "https://example.com/webhook", ChainType: ChainBSC}
+	}
+	assert.NoError(t, valid().Validate())
+
+	tests := []struct {
+		field  string
+		modify func(p *CreatePaymentParams)
+	}{
+		{"order_id", func(p *CreatePaymentParams) { p.OrderID = "" }},
+		{"order_id", func(p *CreatePaymentParams) { p.OrderID = strings.Repeat("x", 65) }},
+		{"amount", func(p *CreatePaymentParams) { p.Amount = 0 }},
+		{"amount", func(p *CreatePaymentParams) { p.Amount = -5 }},
+		{"amount", func(p *CreatePaymentParams) { p.Amount = 0; p.AmountString = "0.00" }},
+		{"notify_url", func(p *CreatePaymentParams) { p.NotifyURL = "" }},
+		{"notify_url", func(p *CreatePaymentParams) { p.NotifyURL = "example.com/webhook" }},
+		{"notify_url", func(p *CreatePaymentParams) { p.NotifyURL = "ftp://example.com" }},
+		{"redirect_url", func(p *CreatePaymentParams) { p.RedirectURL = "/thanks" }},
+		{"chain_type", func(p *CreatePaymentParams) { p.ChainType = "SOLANA" }},
+	}
+	for _, tt := range tests {
+		p := valid()
+		tt.modify(p)
+
+		var validationErr *ValidationError
+		if assert.ErrorAs(t, p.Validate(), &validationErr, tt.field) {
+			assert.Equal(t, tt.field, validationErr.Field)
+		}
+	}
+}
+
+func TestCreatePaymentChainTypeCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(PaymentResponse{StatusCode: 200, Data: &PaymentData{TradeID: "CP1"}})
+	}))
+	defer server.Close()
+
+	params := &CreatePaymentParams{OrderID: "ORDER_001", Amount: 10, NotifyURL: "https://example.com/webhook", ChainType: "SOLANA"}
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+	_, err := client.CreatePayment(params)
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+
+	client = NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL), WithChainTypeCheck(false))
+	_, err = client.CreatePayment(params)
+	assert.NoError(t, err)
+}
+
+func TestClientClone(t *testing.T) {
+	transport := &http.Transport{}
+	base := NewClientWithOptions("sk_base", "base_secret",
+		WithHTTPClient(&http.Client{Transport: transport, Timeout: 30 * time.Second}),
+		WithMaxPageSize(50),
+	)
+
+	tenant := base.Clone(WithCredentials("sk_tenant", "tenant_secret"), WithTimeout(5*time.Second))
+
+	assert.Equal(t, "sk_tenant", tenant.apiKey)
+	assert.Equal(t, "tenant_secret", tenant.apiSecret)
+	assert.Equal(t, 50, tenant.maxPageSize)
+
+	// Transport is shared, client settings are not
+	assert.Same(t, transport, tenant.httpClient.Transport)
+	assert.Equal(t, 5*time.Second, tenant.httpClient.Timeout)
+	assert.Equal(t, 30*time.Second, base.httpClient.Timeout)
+	assert.Equal(t, "sk_base", base.apiKey)
+}
+
+func TestQueryPaymentEmptyData(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status_code":200,"message":"success","data":null,"request_id":"req_1"}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	resp, err := client.QueryPaymentByOrderID("MISSING")
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+	assert.Equal(t, "req_1", resp.RequestID)
+
+	_, err = client.QueryPaymentByTradeID("CP_MISSING")
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+}
+
+func TestRequestBaseURLOverride(t *testing.T) {
+	primaryCalls, canaryCalls := 0, 0
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryCalls++
+		json.NewEncoder(w).Encode(MerchantResponse{StatusCode: 200, Data: &MerchantData{MerchantCode: "M1"}})
+	}))
+	defer primary.Close()
+	canary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		canaryCalls++
+		json.NewEncoder(w).Encode(MerchantResponse{StatusCode: 200, Data: &MerchantData{MerchantCode: "M1"}})
+	}))
+	defer canary.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(primary.URL))
+
+	_, err := client.GetMerchantInfo(WithRequestBaseURL(canary.URL + "/"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, canaryCalls)
+
+	// The client default is untouched
+	_, err = client.GetMerchantInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, primaryCalls)
+	assert.Equal(t, primary.URL, client.baseURL)
+
+	_, err = client.GetMerchantInfo(WithRequestBaseURL("not a url"))
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "base_url", validationErr.Field)
+}
+
+func TestCreatePaymentRequiresHTTPSInProduction(t *testing.T) {
+	client := NewClient("sk_test_key", "test_secret")
+
+	_, err := client.CreatePayment(&CreatePaymentParams{
+		OrderID:   "ORDER_001",
+		Amount:    100,
+		NotifyURL: "http://example.com/webhook",
+	})
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "notify_url", validationErr.Field)
+
+	_, err = client.CreatePayment(&CreatePaymentParams{
+		OrderID:     "ORDER_001",
+		Amount:      100,
+		NotifyURL:   "https://example.com/webhook",
+		RedirectURL: "http://example.com/done",
+	})
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "redirect_url", validationErr.Field)
+}
+
+func TestCreatePaymentAllowsHTTPOffProduction(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(PaymentResponse{StatusCode: 200, Data: &PaymentData{TradeID: "CP1"}})
+	}))
+	defer server.Close()
+
+	params := &CreatePaymentParams{
+		OrderID:   "ORDER_001",
+		Amount:    100,
+		NotifyURL: "http://localhost:8080/webhook",
+	}
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+	_, err := client.CreatePayment(params)
+	assert.NoError(t, err)
+
+	// Opting in enforces HTTPS against any gateway
+	strict := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL), WithRequireHTTPSNotify(true))
+	_, err = strict.CreatePayment(params)
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+}
+
+func TestListOrdersFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "trade_id,status", r.URL.Query().Get("fields"))
+
+		w.Write([]byte(`{"status_code":200,"data":{"list":[{"trade_id":"CP1","status":2}],"total":1}}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	result, err := client.ListOrders(&ListOrdersParams{Fields: []string{"trade_id", "status"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "CP1", result.Data.List[0].TradeID)
+	assert.Empty(t, result.Data.List[0].OrderID)
+
+	_, err = client.ListOrders(&ListOrdersParams{Fields: []string{"trade_id", "secret"}})
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "fields", validationErr.Field)
+}
+
+func TestListOrdersSort(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Write([]byte(`{"status_code":200,"data":{"list":[],"total":0}}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	_, err := client.ListOrders(&ListOrdersParams{SortBy: SortByCreatedAt, SortOrder: SortDesc})
+	assert.NoError(t, err)
+	assert.Equal(t, "created_at", query.Get("sort_by"))
+	assert.Equal(t, "desc", query.Get("sort_order"))
+
+	_, err = client.ListOrders(&ListOrdersParams{})
+	assert.NoError(t, err)
+	assert.False(t, query.Has("sort_by"))
+	assert.False(t, query.Has("sort_order"))
+
+	query = nil
+	tests := []struct {
+		params *ListOrdersParams
+		field  string
+	}{
+		{&ListOrdersParams{SortBy: "secret"}, "sort_by"},
+		{&ListOrdersParams{SortBy: SortByAmount, SortOrder: "up"}, "sort_order"},
+		{&ListOrdersParams{SortOrder: SortAsc}, "sort_order"},
+	}
+	for _, tt := range tests {
+		_, err := client.ListOrders(tt.params)
+		var validationErr *ValidationError
+		if assert.ErrorAs(t, err, &validationErr) {
+			assert.Equal(t, tt.field, validationErr.Field)
+		}
+	}
+	assert.Nil(t, query, "invalid sorts must not reach the server")
+}
+
+func TestListOrdersAmountAndPrefix(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Write([]byte(`{"status_code":200,"data":{"list":[],"total":0}}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	_, err := client.ListOrders(&ListOrdersParams{MinAmount: 10, MaxAmount: 99.5, OrderIDPrefix: "SUBSCRIPTION_"})
+	assert.NoError(t, err)
+	assert.Equal(t, "10", query.Get("min_amount"))
+	assert.Equal(t, "99.5", query.Get("max_amount"))
+	assert.Equal(t, "SUBSCRIPTION_", query.Get("order_id_prefix"))
+
+	// Either bound alone is an open range
+	_, err = client.ListOrders(&ListOrdersParams{MinAmount: 50})
+	assert.NoError(t, err)
+	assert.Equal(t, "50", query.Get("min_amount"))
+	assert.False(t, query.Has("max_amount"))
+
+	query = nil
+	tests := []struct {
+		params *ListOrdersParams
+		field  string
+	}{
+		{&ListOrdersParams{MinAmount: -1}, "min_amount"},
+		{&ListOrdersParams{MaxAmount: -1}, "max_amount"},
+		{&ListOrdersParams{MinAmount: 100, MaxAmount: 10}, "min_amount"},
+	}
+	for _, tt := range tests {
+		_, err := client.ListOrders(tt.params)
+		var validationErr *ValidationError
+		if assert.ErrorAs(t, err, &validationErr) {
+			assert.Equal(t, tt.field, validationErr.Field)
+		}
+	}
+	assert.Nil(t, query, "invalid ranges must not reach the server")
+}
+
+func TestListOrdersTimeRange(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Write([]byte(`{"status_code":200,"data":{"list":[],"total":0}}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+	est := time.FixedZone("EST", -5*3600)
+
+	// Times are sent as their UTC calendar date and win over the strings
+	_, err := client.ListOrders(&ListOrdersParams{
+		StartDate: "2020-01-01",
+		StartTime: time.Date(2025, 12, 1, 23, 30, 0, 0, est),
+		EndTime:   time.Date(2025, 12, 31, 12, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "2025-12-02", query.Get("start_date"))
+	assert.Equal(t, "2025-12-31", query.Get("end_date"))
+
+	_, err = client.ListOrders(&ListOrdersParams{StartDate: "2025-12-01", EndDate: "2025-12-31"})
+	assert.NoError(t, err)
+	assert.Equal(t, "2025-12-01", query.Get("start_date"))
+	assert.Equal(t, "2025-12-31", query.Get("end_date"))
+
+	// The same day is a valid range
+	day := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	_, err = client.ListOrders(&ListOrdersParams{StartTime: day, EndTime: day})
+	assert.NoError(t, err)
+
+	query = nil
+	_, err = client.ListOrders(&ListOrdersParams{StartTime: day.Add(time.Hour), EndTime: day})
+	var validationErr *ValidationError
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, "start_time", validationErr.Field)
+	}
+	assert.Nil(t, query)
+}
+
+func TestListOrdersCursor(t *testing.T) {
+	var cursors []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.False(t, r.URL.Query().Has("page"))
+		cursor := r.URL.Query().Get("cursor")
+		cursors = append(cursors, cursor)
+		if cursor == "" {
+			w.Write([]byte(`{"status_code":200,"data":{"list":[{"trade_id":"CP1"}],"total":2,"next_cursor":"c2"}}`))
+			return
+		}
+		w.Write([]byte(`{"status_code":200,"data":{"list":[{"trade_id":"CP2"}],"total":2}}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+	params := &ListOrdersParams{Page: 3, PageSize: 1, SortBy: SortByPaidAt}
+
+	var tradeIDs []string
+	cursor := ""
+	for {
+		resp, err := client.ListOrdersCursor(context.Background(), params, cursor)
+		if !assert.NoError(t, err) {
+			return
+		}
+		for _, order := range resp.Data.List {
+			tradeIDs = append(tradeIDs, order.TradeID)
+		}
+		if cursor = resp.Data.NextCursor; cursor == "" {
+			break
+		}
+	}
+	assert.Equal(t, []string{"CP1", "CP2"}, tradeIDs)
+	assert.Equal(t, []string{"", "c2"}, cursors)
+	assert.Equal(t, 3, params.Page, "caller params must not be modified")
+
+	_, err := client.ListOrdersCursor(context.Background(), &ListOrdersParams{SortBy: "secret"}, "")
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+}
+
+func TestCreatePaymentAutoOrderID(t *testing.T) {
+	var sentOrderID string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		sentOrderID, _ = body["order_id"].(string)
+
+		json.NewEncoder(w).Encode(PaymentResponse{StatusCode: 200, Data: &PaymentData{OrderID: sentOrderID}})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL), WithAutoOrderID(nil))
+
+	params := &CreatePaymentParams{Amount: 100, NotifyURL: "https://example.com/webhook"}
+	payment, err := client.CreatePayment(params)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(sentOrderID, "ORDER_"))
+	assert.LessOrEqual(t, len(sentOrderID), 64)
+	assert.Equal(t, sentOrderID, payment.GeneratedOrderID)
+	assert.Empty(t, params.OrderID, "caller params must not be modified")
+
+	// An explicit id wins
+	payment, err = client.CreatePayment(&CreatePaymentParams{OrderID: "MINE", Amount: 100, NotifyURL: "https://example.com/webhook"})
+	assert.NoError(t, err)
+	assert.Equal(t, "MINE", sentOrderID)
+	assert.Empty(t, payment.GeneratedOrderID)
+
+	tooLong := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL),
+		WithAutoOrderID(func() string { return strings.Repeat("x", 65) }))
+	_, err = tooLong.CreatePayment(&CreatePaymentParams{Amount: 100, NotifyURL: "https://example.com/webhook"})
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+}
+
+func TestWithNonceSource(t *testing.T) {
+	var nonces []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		nonces = append(nonces, body["nonce"].(string))
+		w.Write([]byte(`{"status_code":200,"data":{"trade_id":"CP1"}}`))
+	}))
+	defer server.Close()
+
+	params := &CreatePaymentParams{OrderID: "ORDER_001", Amount: 100, NotifyURL: "https://example.com/webhook"}
+
+	var n int
+	pinned := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL),
+		WithNonceSource(func() string { n++; return fmt.Sprintf("nonce-%d", n) }))
+	pinned.CreatePayment(params)
+	pinned.CancelOrder("CP1")
+
+	// The default stays random
+	defaults := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+	defaults.CreatePayment(params)
+	reset := pinned.Clone(WithNonceSource(nil))
+	reset.CreatePayment(params)
+
+	if assert.Len(t, nonces, 4) {
+		assert.Equal(t, []string{"nonce-1", "nonce-2"}, nonces[:2])
+		assert.Regexp(t, "^[0-9a-f]{32}$", nonces[2])
+		assert.Regexp(t, "^[0-9a-f]{32}$", nonces[3])
+		assert.NotEqual(t, nonces[2], nonces[3])
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, errors.New("entropy unavailable") }
+
+func TestGenerateNonceEntropyFailure(t *testing.T) {
+	randReader = failingReader{}
+	defer func() { randReader = rand.Reader }()
+
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(`{"status_code":200,"data":{"trade_id":"CP1"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	// Nothing is sent with a predictable nonce
+	_, err := client.CreatePayment(&CreatePaymentParams{OrderID: "ORDER_001", Amount: 100, NotifyURL: "https://example.com/webhook"})
+	assert.ErrorContains(t, err, "entropy unavailable")
+	_, err = client.CancelOrder("CP1")
+	assert.ErrorContains(t, err, "entropy unavailable")
+	_, err = client.GetMerchantInfo()
+	assert.ErrorContains(t, err, "entropy unavailable")
+	assert.Zero(t, calls)
+
+	// Order ids fall back to the clock and a counter
+	first, second := DefaultOrderID(), DefaultOrderID()
+	assert.Regexp(t, "^ORDER_[0-9]{14}_[0-9a-f]{16}$", first)
+	assert.NotEqual(t, first, second)
+}
+
+func TestWithTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(MerchantResponse{StatusCode: 200, Data: &MerchantData{Name: "Shop"}})
+	}))
+	defer server.Close()
+
+	transport := &http.Transport{MaxIdleConnsPerHost: 64}
+	shared := &http.Client{Timeout: 5 * time.Second}
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithHTTPClient(shared),
+		WithTransport(transport),
+	)
+
+	_, err := client.GetMerchantInfo()
+	assert.NoError(t, err)
+	assert.Same(t, transport, client.httpClient.Transport)
+	assert.Equal(t, 5*time.Second, client.httpClient.Timeout)
+	assert.Nil(t, shared.Transport, "the caller's http.Client is not modified")
+}
+
+func TestClientConcurrentUse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(PaymentResponse{StatusCode: 200, Data: &PaymentData{TradeID: "CP1"}})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithTransport(&http.Transport{MaxIdleConnsPerHost: 16}),
+	)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			// Deriving a client for another environment leaves the shared one alone
+			other := client.Clone(WithBaseURL("https://staging.example.com"))
+			assert.Equal(t, "https://staging.example.com", other.baseURL)
+
+			_, err := client.CreatePayment(&CreatePaymentParams{
+				OrderID:   fmt.Sprintf("ORDER_%d", i),
+				Amount:    1,
+				NotifyURL: "https://example.com/webhook",
+			})
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, server.URL, client.baseURL)
+}
+
+func BenchmarkCreatePaymentParallel(b *testing.B) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status_code":200,"data":{"trade_id":"CP1"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithTransport(&http.Transport{MaxIdleConnsPerHost: 64}),
+	)
+	params := &CreatePaymentParams{OrderID: "ORDER_001", Amount: 1, NotifyURL: "https://example.com/webhook"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := client.CreatePayment(params); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
 }
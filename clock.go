@@ -0,0 +1,76 @@
+package cryptomepay
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// minClockSkew is the smallest skew WithClockSync corrects; the Date header
+// only has a resolution of one second
+const minClockSkew = time.Second
+
+// clockSkew holds the skew measured by WithClockSync
+type clockSkew struct {
+	measured atomic.Bool
+	skew     atomic.Int64
+}
+
+// WithTimeOffset adds d to the local clock for the timestamp of signed
+// requests, for hosts whose clock is known to be off: a host 30 seconds
+// behind the gateway needs WithTimeOffset(30 * time.Second). With
+// WithClockSync the offset only applies until the first skew is measured.
+func WithTimeOffset(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeOffset = d
+	}
+}
+
+// WithClockSync corrects the timestamp of signed requests by the skew
+// between the local clock and the gateway's, measured from the Date header
+// of every response, so that a host with a drifting clock is not rejected
+// with a signature or authentication error. The first call, made before
+// any response arrived, uses the local clock or WithTimeOffset. Skews below
+// a second are below the header's resolution and are ignored. Clones
+// measure the skew again.
+func WithClockSync() Option {
+	return func(c *Client) {
+		c.clockSkew = &clockSkew{}
+	}
+}
+
+// now returns the time signed requests are stamped with
+func (c *Client) now() time.Time {
+	if c.clockSkew != nil && c.clockSkew.measured.Load() {
+		return time.Now().Add(time.Duration(c.clockSkew.skew.Load()))
+	}
+	return time.Now().Add(c.timeOffset)
+}
+
+// timestamp returns the timestamp parameter of a signed request
+func (c *Client) timestamp() string {
+	return strconv.FormatInt(c.now().Unix(), 10)
+}
+
+// syncClock measures the skew from the Date header of a response to a
+// request sent at sent and received at received
+func (c *Client) syncClock(header http.Header, sent, received time.Time) {
+	if c.clockSkew == nil {
+		return
+	}
+	date, err := http.ParseTime(header.Get("Date"))
+	if err != nil {
+		return
+	}
+
+	// The server wrote the header somewhere between sent and received, in
+	// the second the header names
+	local := sent.Add(received.Sub(sent) / 2)
+	skew := date.Add(500 * time.Millisecond).Sub(local)
+	if skew > -minClockSkew && skew < minClockSkew {
+		skew = 0
+	}
+	c.clockSkew.skew.Store(int64(skew))
+	c.clockSkew.measured.Store(true)
+}
@@ -0,0 +1,99 @@
+package cryptomepay
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// skewedServer answers CreatePayment with a Date header skew ahead of the
+// local clock, and records the signed timestamps it receives
+func skewedServer(skew time.Duration, timestamps *[]int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Timestamp string `json:"timestamp"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		ts, _ := strconv.ParseInt(body.Timestamp, 10, 64)
+		*timestamps = append(*timestamps, ts)
+
+		w.Header().Set("Date", time.Now().Add(skew).UTC().Format(http.TimeFormat))
+		w.Write([]byte(`{"status_code":200,"data":{"trade_id":"CP1"}}`))
+	}))
+}
+
+// assertNear checks that the unix timestamp ts is within two seconds of want
+func assertNear(t *testing.T, want time.Time, ts int64) {
+	t.Helper()
+	assert.InDelta(t, want.Unix(), ts, 2)
+}
+
+func TestWithTimeOffset(t *testing.T) {
+	var timestamps []int64
+	server := skewedServer(0, &timestamps)
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL), WithTimeOffset(time.Hour))
+	params := &CreatePaymentParams{OrderID: "ORDER_001", Amount: 1, NotifyURL: "https://example.com/webhook"}
+
+	_, err := client.CreatePayment(params)
+	assert.NoError(t, err)
+	_, err = client.CreatePayment(params)
+	assert.NoError(t, err)
+	if assert.Len(t, timestamps, 2) {
+		assertNear(t, time.Now().Add(time.Hour), timestamps[0])
+		assertNear(t, time.Now().Add(time.Hour), timestamps[1])
+	}
+}
+
+func TestWithClockSync(t *testing.T) {
+	var timestamps []int64
+	server := skewedServer(time.Hour, &timestamps)
+	defer server.Close()
+
+	params := &CreatePaymentParams{OrderID: "ORDER_001", Amount: 1, NotifyURL: "https://example.com/webhook"}
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL), WithClockSync())
+	for i := 0; i < 3; i++ {
+		_, err := client.CreatePayment(params)
+		assert.NoError(t, err)
+	}
+
+	// Clones measure the skew again
+	_, err := client.Clone().CreatePayment(params)
+	assert.NoError(t, err)
+
+	if assert.Len(t, timestamps, 4) {
+		assertNear(t, time.Now(), timestamps[0])
+		assertNear(t, time.Now().Add(time.Hour), timestamps[1])
+		assertNear(t, time.Now().Add(time.Hour), timestamps[2])
+		assertNear(t, time.Now(), timestamps[3])
+	}
+}
+
+func TestWithClockSyncReplacesOffset(t *testing.T) {
+	var timestamps []int64
+	server := skewedServer(0, &timestamps)
+	defer server.Close()
+
+	params := &CreatePaymentParams{OrderID: "ORDER_001", Amount: 1, NotifyURL: "https://example.com/webhook"}
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithTimeOffset(-time.Hour),
+		WithClockSync(),
+	)
+	for i := 0; i < 2; i++ {
+		_, err := client.CreatePayment(params)
+		assert.NoError(t, err)
+	}
+
+	if assert.Len(t, timestamps, 2) {
+		assertNear(t, time.Now().Add(-time.Hour), timestamps[0])
+		assertNear(t, time.Now(), timestamps[1])
+	}
+	assert.Zero(t, client.clockSkew.skew.Load(), "skews below a second are ignored")
+}
@@ -0,0 +1,115 @@
+package cryptomepay
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// acceptEncoding is sent unless the caller sets its own Accept-Encoding
+const acceptEncoding = "gzip, deflate"
+
+// DefaultMaxResponseSize is the largest response body read, before and
+// after decompression, unless WithMaxResponseSize says otherwise
+const DefaultMaxResponseSize = 32 << 20
+
+// ErrResponseTooLarge is returned when a response body, or the result of
+// decompressing it, exceeds the client's max response size
+var ErrResponseTooLarge = errors.New("cryptomepay: response body too large")
+
+// WithMaxResponseSize bounds response bodies to n bytes, both as received
+// and once decompressed, so a small gzip body cannot expand without limit.
+// Values <= 0 are ignored.
+func WithMaxResponseSize(n int64) Option {
+	return func(c *Client) {
+		if n > 0 {
+			c.maxResponseSize = n
+		}
+	}
+}
+
+// readLimited reads r to the end, failing with ErrResponseTooLarge once
+// more than max bytes arrive
+func readLimited(r io.Reader, max int64) ([]byte, error) {
+	b, err := io.ReadAll(io.LimitReader(r, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > max {
+		return nil, fmt.Errorf("%w: over %d bytes", ErrResponseTooLarge, max)
+	}
+	return b, nil
+}
+
+// WithRequestCompression gzips request bodies of at least minBytes bytes,
+// such as large BulkCreatePayments calls, and sends them with
+// Content-Encoding: gzip. Only enable it for gateways known to accept
+// compressed bodies. Signatures cover the parameters, not the encoded
+// body, so they are unaffected. A minBytes <= 0 disables compression,
+// which is the default.
+func WithRequestCompression(minBytes int) Option {
+	return func(c *Client) {
+		c.requestCompression = minBytes
+	}
+}
+
+// compressBody gzips body when compression is enabled and body is large
+// enough, returning the bytes to send and whether they are compressed
+func (c *Client) compressBody(body []byte) ([]byte, bool, error) {
+	if c.requestCompression <= 0 || len(body) < c.requestCompression {
+		return body, false, nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(body); err != nil {
+		return nil, false, fmt.Errorf("failed to compress request body: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, false, fmt.Errorf("failed to compress request body: %w", err)
+	}
+	return buf.Bytes(), true, nil
+}
+
+// decodeBody undoes the Content-Encoding in header, for example of a
+// response the transport did not already decompress, and removes the header
+// so it describes the decoded body. Unknown encodings are left as they are.
+// A decoded body over max bytes fails with ErrResponseTooLarge.
+func decodeBody(header http.Header, body []byte, max int64) ([]byte, error) {
+	var r io.Reader
+	switch strings.ToLower(strings.TrimSpace(header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress body: %w", err)
+		}
+		r = zr
+	case "deflate":
+		// deflate is zlib wrapped, but some servers send raw DEFLATE
+		zr, err := zlib.NewReader(bytes.NewReader(body))
+		if err != nil {
+			r = flate.NewReader(bytes.NewReader(body))
+		} else {
+			r = zr
+		}
+	default:
+		return body, nil
+	}
+
+	decoded, err := readLimited(r, max)
+	if errors.Is(err, ErrResponseTooLarge) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress body: %w", err)
+	}
+	header.Del("Content-Encoding")
+	header.Del("Content-Length")
+	return decoded, nil
+}
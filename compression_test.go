@@ -0,0 +1,129 @@
+package cryptomepay
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressedResponses(t *testing.T) {
+	body := `{"status_code":200,"data":{"name":"Shop"}}`
+	var acceptEncoding string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptEncoding = r.Header.Get("Accept-Encoding")
+
+		// The encoding is the first path segment, e.g. /gzip/merchant/info
+		encoding := strings.Split(r.URL.Path, "/")[1]
+		var buf bytes.Buffer
+		zw := io.WriteCloser(gzip.NewWriter(&buf))
+		if encoding == "deflate" {
+			zw = zlib.NewWriter(&buf)
+		}
+		zw.Write([]byte(body))
+		zw.Close()
+
+		w.Header().Set("Content-Encoding", encoding)
+		w.Write(buf.Bytes())
+	}))
+	defer server.Close()
+
+	for _, encoding := range []string{"gzip", "deflate"} {
+		client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+		resp, err := client.GetMerchantInfo(WithRequestBaseURL(server.URL + "/" + encoding))
+		if assert.NoError(t, err, encoding) {
+			assert.Equal(t, "Shop", resp.Data.Name)
+			assert.Equal(t, body, string(resp.Raw.Body), "Raw holds the decompressed body")
+			assert.Empty(t, resp.Raw.Header.Get("Content-Encoding"))
+		}
+		assert.Equal(t, "gzip, deflate", acceptEncoding)
+	}
+
+	// A caller's Accept-Encoding is kept
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+	client.GetMerchantInfo(WithRequestHeader("Accept-Encoding", "gzip"), WithRequestBaseURL(server.URL+"/gzip"))
+	assert.Equal(t, "gzip", acceptEncoding)
+}
+
+func TestCompressedResponseInvalid(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write([]byte("not gzip"))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+	_, err := client.GetMerchantInfo()
+	assert.ErrorContains(t, err, "failed to decompress body")
+}
+
+func TestCompressedResponseTooLarge(t *testing.T) {
+	// 64 MiB of zeros gzips to about 64 KiB
+	var bomb bytes.Buffer
+	zw := gzip.NewWriter(&bomb)
+	zw.Write(make([]byte, 64<<20))
+	zw.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/plain/merchant/info" {
+			w.Write([]byte(`{"status_code":200,"data":{"name":"` + strings.Repeat("x", 2048) + `"}}`))
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(bomb.Bytes())
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+	_, err := client.GetMerchantInfo()
+	assert.ErrorIs(t, err, ErrResponseTooLarge)
+
+	// Plain bodies are bounded too
+	small := client.Clone(WithMaxResponseSize(1024))
+	_, err = small.GetMerchantInfo(WithRequestBaseURL(server.URL + "/plain"))
+	assert.ErrorIs(t, err, ErrResponseTooLarge)
+
+	resp, err := client.GetMerchantInfo(WithRequestBaseURL(server.URL + "/plain"))
+	assert.NoError(t, err)
+	assert.Len(t, resp.Data.Name, 2048)
+}
+
+func TestWithRequestCompression(t *testing.T) {
+	var encodings []string
+	var orderIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encodings = append(encodings, r.Header.Get("Content-Encoding"))
+		reader := io.Reader(r.Body)
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if !assert.NoError(t, err) {
+				return
+			}
+			reader = zr
+		}
+		var body map[string]interface{}
+		assert.NoError(t, json.NewDecoder(reader).Decode(&body))
+		orderIDs = append(orderIDs, body["order_id"].(string))
+		w.Write([]byte(`{"status_code":200,"data":{"trade_id":"CP1"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL), WithRequestCompression(512))
+
+	small := &CreatePaymentParams{OrderID: "SMALL", Amount: 1, NotifyURL: "https://example.com/webhook"}
+	large := &CreatePaymentParams{OrderID: "LARGE", Amount: 1, NotifyURL: "https://example.com/webhook?pad=" + strings.Repeat("x", 600)}
+	_, err := client.CreatePayment(small)
+	assert.NoError(t, err)
+	_, err = client.CreatePayment(large)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"", "gzip"}, encodings)
+	assert.Equal(t, []string{"SMALL", "LARGE"}, orderIDs)
+}
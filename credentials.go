@@ -0,0 +1,101 @@
+package cryptomepay
+
+import "strings"
+
+// credentials is an api key and the secret that goes with it, read once
+// per call so a rotation cannot pair one key with another key's secret
+type credentials struct {
+	apiKey    string
+	apiSecret string
+}
+
+// WithCredentialProvider reads the api key and secret from fn instead of
+// the values given to NewClient, so they can be rotated without rebuilding
+// a shared client. fn is called once per API call, and for each webhook or
+// response signature check, from concurrent goroutines; it must be safe for
+// concurrent use and should be fast, for example by returning values held
+// in an atomic.Value.
+//
+// While keys rotate, webhooks signed with the previous secret no longer
+// verify. Bearer tokens from Authenticate stay in use until they expire.
+// A nil fn, or a later WithCredentials, restores the static credentials.
+func WithCredentialProvider(fn func() (apiKey, apiSecret string)) Option {
+	return func(c *Client) {
+		c.credentialProvider = fn
+	}
+}
+
+// credentials returns the credentials to use for one call
+func (c *Client) credentials() credentials {
+	if c.credentialProvider != nil {
+		apiKey, apiSecret := c.credentialProvider()
+		return credentials{apiKey: apiKey, apiSecret: apiSecret}
+	}
+	return credentials{apiKey: c.apiKey, apiSecret: c.apiSecret}
+}
+
+// withAPIKey authenticates a call with the api key its body was signed for
+func withAPIKey(apiKey string) RequestOption {
+	return func(o *requestOptions) {
+		o.apiKey = apiKey
+	}
+}
+
+// WithAPIKeyPrefix makes NewClientChecked require the api key to start
+// with one of prefixes, such as "sk_live_" in production, so a test key or
+// a secret passed as the key is caught at startup. Key formats are not
+// fixed by the gateway, so no prefix is checked by default. Other
+// constructors ignore it.
+func WithAPIKeyPrefix(prefixes ...string) Option {
+	return func(c *Client) {
+		c.apiKeyPrefixes = prefixes
+	}
+}
+
+// NewClientChecked is NewClientWithOptions that rejects credentials which
+// cannot work, so misconfiguration fails at startup rather than on the first
+// request. It returns a *ValidationError when the api key or secret is
+// empty, has leading or trailing whitespace, or when both are the same; the
+// latter usually means a variable was copied into both. With
+// WithAPIKeyPrefix the api key must also have one of the given prefixes.
+// With WithCredentialProvider in opts the static credentials are not
+// checked.
+func NewClientChecked(apiKey, apiSecret string, opts ...Option) (*Client, error) {
+	c := NewClientWithOptions(apiKey, apiSecret, opts...)
+	if c.credentialProvider != nil {
+		return c, nil
+	}
+	if err := checkCredential("api_key", c.apiKey); err != nil {
+		return nil, err
+	}
+	if err := checkCredential("api_secret", c.apiSecret); err != nil {
+		return nil, err
+	}
+	if c.apiKey == c.apiSecret {
+		return nil, &ValidationError{Field: "api_secret", Message: "must differ from the api key"}
+	}
+	if len(c.apiKeyPrefixes) > 0 && !hasAnyPrefix(c.apiKey, c.apiKeyPrefixes) {
+		return nil, &ValidationError{Field: "api_key", Message: "must start with " + strings.Join(c.apiKeyPrefixes, " or ")}
+	}
+	return c, nil
+}
+
+// checkCredential rejects an empty or whitespace padded credential
+func checkCredential(field, value string) error {
+	switch {
+	case value == "":
+		return &ValidationError{Field: field, Message: "is required"}
+	case strings.TrimSpace(value) != value:
+		return &ValidationError{Field: field, Message: "has leading or trailing whitespace"}
+	}
+	return nil
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
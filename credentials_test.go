@@ -0,0 +1,137 @@
+package cryptomepay
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithCredentialProvider(t *testing.T) {
+	secrets := map[string]string{"sk_one": "secret_one", "sk_two": "secret_two"}
+
+	var mismatches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		apiKey := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+		// The header, the signed api_key and the signing secret must agree
+		params := map[string]string{}
+		for k, v := range body {
+			if s, ok := v.(string); ok {
+				params[k] = s
+			}
+		}
+		params["amount"] = "1.00"
+		if body["api_key"] != apiKey || signHMAC(secrets[apiKey], SigningString(params)) != body["signature"] {
+			atomic.AddInt32(&mismatches, 1)
+		}
+		w.Write([]byte(`{"status_code":200,"data":{"trade_id":"CP1"}}`))
+	}))
+	defer server.Close()
+
+	var current atomic.Value
+	current.Store([2]string{"sk_one", "secret_one"})
+	client := NewClientWithOptions("sk_static", "static_secret",
+		WithBaseURL(server.URL),
+		WithCredentialProvider(func() (string, string) {
+			creds := current.Load().([2]string)
+			return creds[0], creds[1]
+		}),
+	)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%5 == 0 {
+				if i%10 == 0 {
+					current.Store([2]string{"sk_two", "secret_two"})
+				} else {
+					current.Store([2]string{"sk_one", "secret_one"})
+				}
+			}
+			_, err := client.CreatePayment(&CreatePaymentParams{OrderID: "ORDER_001", Amount: 1, NotifyURL: "https://example.com/webhook"})
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(0), mismatches)
+}
+
+func TestCredentialProviderFallback(t *testing.T) {
+	var auth []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = append(auth, r.Header.Get("Authorization"))
+		w.Write([]byte(`{"status_code":200,"data":{"name":"Shop"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_static", "static_secret", WithBaseURL(server.URL))
+	rotated := client.Clone(WithCredentialProvider(func() (string, string) { return "sk_rotated", "rotated_secret" }))
+	reset := rotated.Clone(WithCredentials("sk_new", "new_secret"))
+
+	client.GetMerchantInfo()
+	rotated.GetMerchantInfo()
+	reset.GetMerchantInfo()
+	assert.Equal(t, []string{"Bearer sk_static", "Bearer sk_rotated", "Bearer sk_new"}, auth)
+
+	// Webhooks are verified with the provider's secret
+	params := map[string]string{"trade_id": "CP1", "status": "2"}
+	payload := &WebhookPayload{TradeID: "CP1", Status: StatusPaid, Signature: signHMAC("rotated_secret", SigningString(params))}
+	assert.True(t, rotated.VerifyWebhookSignature(payload))
+	assert.False(t, client.VerifyWebhookSignature(payload))
+}
+
+func TestNewClientChecked(t *testing.T) {
+	client, err := NewClientChecked("sk_test_key", "test_secret", WithBaseURL("https://api.example.com"))
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api.example.com", client.baseURL)
+
+	tests := []struct {
+		apiKey, apiSecret string
+		field             string
+	}{
+		{"", "test_secret", "api_key"},
+		{"sk_test_key", "", "api_secret"},
+		{"sk_test_key\n", "test_secret", "api_key"},
+		{"sk_test_key", " test_secret", "api_secret"},
+		{"sk_test_key", "sk_test_key", "api_secret"},
+	}
+	for _, tt := range tests {
+		_, err := NewClientChecked(tt.apiKey, tt.apiSecret)
+		var validationErr *ValidationError
+		if assert.ErrorAs(t, err, &validationErr, "%q %q", tt.apiKey, tt.apiSecret) {
+			assert.Equal(t, tt.field, validationErr.Field)
+		}
+	}
+
+	// Credentials from options are the ones checked
+	_, err = NewClientChecked("", "", WithCredentials("sk_test_key", "test_secret"))
+	assert.NoError(t, err)
+	_, err = NewClientChecked("", "", WithCredentialProvider(func() (string, string) { return "sk_test_key", "test_secret" }))
+	assert.NoError(t, err)
+}
+
+func TestNewClientCheckedAPIKeyPrefix(t *testing.T) {
+	_, err := NewClientChecked("sk_test_key", "test_secret", WithAPIKeyPrefix("sk_test_", "sk_live_"))
+	assert.NoError(t, err)
+
+	_, err = NewClientChecked("test_secret", "sk_test_key", WithAPIKeyPrefix("sk_test_", "sk_live_"))
+	var validationErr *ValidationError
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, "api_key", validationErr.Field)
+		assert.EqualError(t, err, "cryptomepay: invalid api_key: must start with sk_test_ or sk_live_")
+	}
+
+	// Only the checked constructor looks at the prefix
+	client := NewClientWithOptions("ak_1234", "test_secret", WithAPIKeyPrefix("sk_live_"))
+	assert.Equal(t, "ak_1234", client.apiKey)
+}
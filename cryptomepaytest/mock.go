@@ -0,0 +1,182 @@
+// Package cryptomepaytest provides an in-memory Cryptome Pay gateway for
+// testing code that uses the cryptomepay client.
+//
+//	srv := cryptomepaytest.NewMockServer(cryptomepay.WithRetry(3, time.Millisecond))
+//	defer srv.Close()
+//
+//	srv.On("POST", "/order/create-transaction",
+//	    cryptomepaytest.Error(http.StatusTooManyRequests, 429, "slow down"),
+//	    cryptomepaytest.Error(http.StatusTooManyRequests, 429, "slow down"),
+//	    cryptomepaytest.OK(cryptomepay.PaymentData{TradeID: "CP1"}),
+//	)
+//
+//	resp, err := srv.Client.CreatePayment(params)
+package cryptomepaytest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+
+	cryptomepay "github.com/cryptome-ai/cryptome-pay-go"
+)
+
+// Credentials of the client returned by NewMockServer
+const (
+	APIKey    = "sk_test_mock"
+	APISecret = "mock_secret"
+)
+
+// Response is a canned reply to a request
+type Response struct {
+	// Status is the HTTP status code, 200 when zero
+	Status int
+
+	// Body is sent as is when it is a string or []byte and as JSON
+	// otherwise. A nil Body gets an envelope with the status as status_code.
+	Body interface{}
+
+	Header http.Header
+}
+
+// OK returns a 200 response wrapping data in the API envelope
+func OK(data interface{}) Response {
+	return Response{Body: map[string]interface{}{"status_code": 200, "message": "success", "data": data}}
+}
+
+// Error returns a response with HTTP status httpStatus and API error code
+func Error(httpStatus, code int, message string) Response {
+	return Response{Status: httpStatus, Body: map[string]interface{}{"status_code": code, "message": message}}
+}
+
+// Request is a request received by the mock server
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// Params decodes a JSON request body
+func (r *Request) Params() (map[string]interface{}, error) {
+	var params map[string]interface{}
+	if err := json.Unmarshal(r.Body, &params); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// Server is a mock gateway with a client pointed at it
+type Server struct {
+	*httptest.Server
+
+	// Client talks to the server with APIKey and APISecret
+	Client *cryptomepay.Client
+
+	mu        sync.Mutex
+	responses map[string][]Response
+	requests  []*Request
+}
+
+// NewMockServer starts a mock gateway and returns it with a client using
+// opts on top of the server's base URL. Endpoints answer 404 until
+// scripted with On.
+func NewMockServer(opts ...cryptomepay.Option) *Server {
+	s := &Server{responses: make(map[string][]Response)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	opts = append([]cryptomepay.Option{cryptomepay.WithBaseURL(s.URL)}, opts...)
+	s.Client = cryptomepay.NewClientWithOptions(APIKey, APISecret, opts...)
+	return s
+}
+
+// On scripts the replies to method and path, e.g. "/merchant/info". The
+// responses are used in order and the last one repeats, so two 429s
+// followed by OK fail twice and then succeed for every later call. A new
+// script replaces any earlier one for the endpoint.
+func (s *Server) On(method, path string, responses ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[method+" "+path] = responses
+}
+
+// Requests returns the requests received so far, oldest first
+func (s *Server) Requests() []*Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Request(nil), s.requests...)
+}
+
+// LastRequest returns the most recent request, nil if none arrived
+func (s *Server) LastRequest() *Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.requests) == 0 {
+		return nil
+	}
+	return s.requests[len(s.requests)-1]
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	req := &Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		Header: r.Header.Clone(),
+		Body:   body,
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	key := r.Method + " " + r.URL.Path
+	queue := s.responses[key]
+	resp := Error(http.StatusNotFound, http.StatusNotFound, fmt.Sprintf("no mock response for %s", key))
+	if len(queue) > 0 {
+		resp = queue[0]
+		if len(queue) > 1 {
+			s.responses[key] = queue[1:]
+		}
+	}
+	s.mu.Unlock()
+
+	writeResponse(w, resp)
+}
+
+func writeResponse(w http.ResponseWriter, resp Response) {
+	status := resp.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	var body []byte
+	switch b := resp.Body.(type) {
+	case nil:
+		body, _ = json.Marshal(map[string]interface{}{"status_code": status, "message": http.StatusText(status)})
+	case string:
+		body = []byte(b)
+	case []byte:
+		body = b
+	default:
+		var err error
+		if body, err = json.Marshal(b); err != nil {
+			status = http.StatusInternalServerError
+			body = []byte(strconv.Quote(err.Error()))
+		}
+	}
+
+	for k, v := range resp.Header {
+		w.Header()[k] = v
+	}
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.WriteHeader(status)
+	w.Write(body)
+}
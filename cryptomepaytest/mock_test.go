@@ -0,0 +1,110 @@
+package cryptomepaytest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	cryptomepay "github.com/cryptome-ai/cryptome-pay-go"
+	"github.com/stretchr/testify/assert"
+)
+
+var testPayment = &cryptomepay.CreatePaymentParams{
+	OrderID:   "ORDER_001",
+	Amount:    100,
+	NotifyURL: "https://example.com/webhook",
+	ChainType: cryptomepay.ChainBSC,
+}
+
+func TestMockServerRetryScenario(t *testing.T) {
+	srv := NewMockServer(cryptomepay.WithRetry(3, time.Millisecond))
+	defer srv.Close()
+
+	srv.On("POST", "/order/create-transaction",
+		Error(http.StatusTooManyRequests, 429, "slow down"),
+		Error(http.StatusTooManyRequests, 429, "slow down"),
+		OK(cryptomepay.PaymentData{TradeID: "CP1", OrderID: "ORDER_001"}),
+	)
+
+	resp, err := srv.Client.CreatePayment(testPayment)
+	assert.NoError(t, err)
+	assert.Equal(t, "CP1", resp.Data.TradeID)
+	assert.Equal(t, 3, resp.Attempts)
+
+	requests := srv.Requests()
+	assert.Len(t, requests, 3)
+	for _, r := range requests {
+		assert.Equal(t, "/order/create-transaction", r.Path)
+		assert.NoError(t, srv.VerifySignature(r))
+	}
+
+	// The last response repeats
+	_, err = srv.Client.CreatePayment(testPayment)
+	assert.NoError(t, err)
+}
+
+func TestMockServerUnscripted(t *testing.T) {
+	srv := NewMockServer()
+	defer srv.Close()
+
+	_, err := srv.Client.GetMerchantInfo()
+	var apiErr *cryptomepay.APIError
+	if assert.True(t, errors.As(err, &apiErr)) {
+		assert.Equal(t, http.StatusNotFound, apiErr.HTTPStatus)
+	}
+	assert.Equal(t, "GET", srv.LastRequest().Method)
+}
+
+func TestMockServerVerifySignature(t *testing.T) {
+	srv := NewMockServer()
+	defer srv.Close()
+	srv.On("POST", "/order/cancel-transaction", OK(cryptomepay.OrderData{TradeID: "CP1"}))
+	srv.On("GET", "/merchant/order/query", OK(cryptomepay.OrderData{TradeID: "CP1", Status: cryptomepay.StatusPaid, ActualAmount: 15.625}))
+	srv.On("POST", "/order/refund", OK(cryptomepay.RefundData{RefundID: "RF1"}))
+	srv.On("POST", "/order/bulk-create-transaction", OK(cryptomepay.BulkPaymentData{}))
+
+	_, err := srv.Client.CancelOrder("CP1")
+	assert.NoError(t, err)
+	assert.NoError(t, srv.VerifySignature(srv.LastRequest()))
+
+	_, err = srv.Client.RefundOrder("CP1", &cryptomepay.RefundParams{Amount: 0.625, Address: "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"})
+	assert.NoError(t, err)
+	assert.NoError(t, srv.VerifySignature(srv.LastRequest()))
+
+	_, err = srv.Client.BulkCreatePayments(context.Background(), []*cryptomepay.CreatePaymentParams{testPayment})
+	assert.NoError(t, err)
+	assert.NoError(t, srv.VerifySignature(srv.LastRequest()))
+
+	withQuery := *testPayment
+	withQuery.NotifyURL = "https://example.com/webhook?a=1&b=2"
+	_, err = srv.Client.BulkCreatePayments(context.Background(), []*cryptomepay.CreatePaymentParams{&withQuery})
+	assert.NoError(t, err)
+	assert.NoError(t, srv.VerifySignature(srv.LastRequest()))
+
+	// A client with another secret is rejected
+	other := srv.Client.Clone(cryptomepay.WithCredentials(APIKey, "wrong"))
+	_, err = other.CancelOrder("CP1")
+	assert.NoError(t, err)
+	assert.Error(t, srv.VerifySignature(srv.LastRequest()))
+
+	// As is a request without a signature
+	_, err = srv.Client.GetMerchantInfo()
+	assert.Error(t, err)
+	assert.Error(t, srv.VerifySignature(srv.LastRequest()))
+}
+
+func TestMockServerRawResponse(t *testing.T) {
+	srv := NewMockServer()
+	defer srv.Close()
+	srv.On("GET", "/merchant/info", Response{
+		Body:   `{"status_code":200,"data":{"name":"Shop"}}`,
+		Header: http.Header{"X-Request-Id": {"req_1"}},
+	})
+
+	resp, err := srv.Client.GetMerchantInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "Shop", resp.Data.Name)
+	assert.Equal(t, "req_1", resp.Raw.Header.Get("X-Request-Id"))
+}
@@ -0,0 +1,95 @@
+package cryptomepaytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	cryptomepay "github.com/cryptome-ai/cryptome-pay-go"
+)
+
+// VerifySignature checks that a JSON request body carries a valid
+// signature under APISecret with the default HMAC-SHA256 signer. It
+// rebuilds the signed parameters the way the gateway does: strings as
+// sent, numbers in the form the client signs and nested values as compact
+// JSON.
+func (s *Server) VerifySignature(r *Request) error {
+	body, err := r.Params()
+	if err != nil {
+		return fmt.Errorf("cryptomepaytest: request body is not JSON: %w", err)
+	}
+
+	signature, _ := body["signature"].(string)
+	if signature == "" {
+		return errors.New("cryptomepaytest: request has no signature")
+	}
+
+	params := make(map[string]string, len(body))
+	numbers := make(map[string][]string)
+	for k, v := range body {
+		switch val := v.(type) {
+		case string:
+			params[k] = val
+		case float64:
+			numbers[k] = numberVariants(val)
+		case nil:
+		default:
+			// Nested values are signed without HTML escaping, so a URL
+			// keeps its & rather than becoming \u0026
+			var buf bytes.Buffer
+			enc := json.NewEncoder(&buf)
+			enc.SetEscapeHTML(false)
+			if err := enc.Encode(val); err != nil {
+				return fmt.Errorf("cryptomepaytest: cannot encode %s: %w", k, err)
+			}
+			params[k] = strings.TrimSuffix(buf.String(), "\n")
+		}
+	}
+
+	keys := make([]string, 0, len(numbers))
+	for k := range numbers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	if !verifyVariants(params, keys, numbers, signature) {
+		return fmt.Errorf("cryptomepaytest: signature does not match, signed over %q", cryptomepay.SigningString(params))
+	}
+	return nil
+}
+
+// numberVariants returns the renderings the client signs numbers with: 2
+// decimals for fiat amounts, 4 for crypto amounts, and the shortest form
+func numberVariants(v float64) []string {
+	var variants []string
+	seen := make(map[string]bool, 3)
+	for _, decimals := range []int{2, 4, -1} {
+		s := strconv.FormatFloat(v, 'f', decimals, 64)
+		if !seen[s] {
+			seen[s] = true
+			variants = append(variants, s)
+		}
+	}
+	return variants
+}
+
+// verifyVariants tries every combination of number renderings for keys
+func verifyVariants(params map[string]string, keys []string, numbers map[string][]string, signature string) bool {
+	if len(keys) == 0 {
+		return cryptomepay.VerifyAgainstKnownVector(APISecret, params, signature) == nil
+	}
+
+	key := keys[0]
+	for _, variant := range numbers[key] {
+		params[key] = variant
+		if verifyVariants(params, keys[1:], numbers, signature) {
+			return true
+		}
+	}
+	delete(params, key)
+	return false
+}
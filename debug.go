@@ -0,0 +1,72 @@
+package cryptomepay
+
+import "fmt"
+
+// DebugString returns a compact, secret-free summary of the response
+func (r *PaymentResponse) DebugString() string {
+	if r == nil {
+		return "<nil>"
+	}
+	s := debugEnvelope(r.StatusCode, r.Message, r.RequestID)
+	if r.Data != nil {
+		s += fmt.Sprintf(" trade_id=%s order_id=%s chain_type=%s", r.Data.TradeID, r.Data.OrderID, r.Data.ChainType)
+	}
+	return s
+}
+
+// DebugString returns a compact, secret-free summary of the response
+func (r *OrderResponse) DebugString() string {
+	if r == nil {
+		return "<nil>"
+	}
+	s := debugEnvelope(r.StatusCode, r.Message, r.RequestID)
+	if r.Data != nil {
+		s += fmt.Sprintf(" trade_id=%s order_id=%s order_status=%d", r.Data.TradeID, r.Data.OrderID, r.Data.Status)
+	}
+	return s
+}
+
+// DebugString returns a compact, secret-free summary of the response
+func (r *OrderListResponse) DebugString() string {
+	if r == nil {
+		return "<nil>"
+	}
+	s := debugEnvelope(r.StatusCode, r.Message, r.RequestID)
+	if r.Data != nil {
+		s += fmt.Sprintf(" total=%d page=%d page_size=%d count=%d", r.Data.Total, r.Data.Page, r.Data.PageSize, len(r.Data.List))
+	}
+	return s
+}
+
+// DebugString returns a compact, secret-free summary of the response.
+// The merchant email is omitted.
+func (r *MerchantResponse) DebugString() string {
+	if r == nil {
+		return "<nil>"
+	}
+	s := debugEnvelope(r.StatusCode, r.Message, r.RequestID)
+	if r.Data != nil {
+		s += fmt.Sprintf(" merchant_code=%s merchant_status=%d kyc_status=%s", r.Data.MerchantCode, r.Data.Status, r.Data.KYCStatus)
+	}
+	return s
+}
+
+// DebugString returns a compact summary of the error
+func (e *APIError) DebugString() string {
+	if e == nil {
+		return "<nil>"
+	}
+	s := debugEnvelope(e.StatusCode, e.Message, e.RequestID)
+	if e.HTTPStatus != 0 {
+		s += fmt.Sprintf(" http_status=%d", e.HTTPStatus)
+	}
+	for _, fe := range e.Errors {
+		s += fmt.Sprintf(" field_error=%s:%q", fe.Field, fe.Message)
+	}
+	return s
+}
+
+// debugEnvelope formats the fields shared by every response envelope
+func debugEnvelope(code int, message, requestID string) string {
+	return fmt.Sprintf("code=%d message=%q request_id=%s", code, message, requestID)
+}
@@ -0,0 +1,32 @@
+package cryptomepay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebugString(t *testing.T) {
+	payment := &PaymentResponse{
+		StatusCode: 200,
+		Message:    "success",
+		RequestID:  "req_1",
+		Data:       &PaymentData{TradeID: "CP1", OrderID: "O1", ChainType: ChainBSC},
+	}
+	assert.Equal(t, `code=200 message="success" request_id=req_1 trade_id=CP1 order_id=O1 chain_type=BSC`, payment.DebugString())
+
+	order := &OrderResponse{StatusCode: 200, Message: "success", Data: &OrderData{TradeID: "CP1", OrderID: "O1", Status: StatusPaid}}
+	assert.Equal(t, `code=200 message="success" request_id= trade_id=CP1 order_id=O1 order_status=2`, order.DebugString())
+
+	list := &OrderListResponse{StatusCode: 200, Data: &OrderListData{List: []OrderData{{}, {}}, Total: 10, Page: 1, PageSize: 2}}
+	assert.Equal(t, `code=200 message="" request_id= total=10 page=1 page_size=2 count=2`, list.DebugString())
+
+	merchant := &MerchantResponse{StatusCode: 200, Data: &MerchantData{MerchantCode: "M1", Email: "owner@example.com"}}
+	assert.NotContains(t, merchant.DebugString(), "owner@example.com")
+
+	apiErr := &APIError{StatusCode: ErrCodeInvalidAmount, Message: "invalid amount", RequestID: "req_2", HTTPStatus: 400}
+	assert.Equal(t, `code=10004 message="invalid amount" request_id=req_2 http_status=400`, apiErr.DebugString())
+
+	var nilOrder *OrderResponse
+	assert.Equal(t, "<nil>", nilOrder.DebugString())
+}
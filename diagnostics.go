@@ -0,0 +1,70 @@
+package cryptomepay
+
+import (
+	"runtime"
+	"time"
+)
+
+// Diagnostics describes the SDK and client configuration for bug reports.
+// It never contains the api secret.
+type Diagnostics struct {
+	SDKVersion        string        `json:"sdk_version"`
+	GoVersion         string        `json:"go_version"`
+	Platform          string        `json:"platform"`
+	BaseURL           string        `json:"base_url"`
+	Environment       string        `json:"environment"`
+	Timeout           time.Duration `json:"timeout"`
+	PerAttemptTimeout time.Duration `json:"per_attempt_timeout"`
+	MaxAttempts       int           `json:"max_attempts"`
+	RetryBaseDelay    time.Duration `json:"retry_base_delay"`
+	RetryMaxDelay     time.Duration `json:"retry_max_delay"`
+	RateLimit         float64       `json:"rate_limit"`
+	RateLimitBurst    int           `json:"rate_limit_burst"`
+	MaxPageSize       int           `json:"max_page_size"`
+	APIKeyPrefix      string        `json:"api_key_prefix"`
+}
+
+// Diagnostics returns the SDK, runtime and redacted client configuration
+func (c *Client) Diagnostics() Diagnostics {
+	environment := "custom"
+	if c.baseURL == ProductionURL {
+		environment = "production"
+	}
+
+	var rateLimit float64
+	var rateLimitBurst int
+	if c.limiter != nil {
+		rateLimit = float64(c.limiter.Limit())
+		rateLimitBurst = c.limiter.Burst()
+	}
+
+	return Diagnostics{
+		SDKVersion:        Version,
+		GoVersion:         runtime.Version(),
+		Platform:          runtime.GOOS + "/" + runtime.GOARCH,
+		BaseURL:           c.baseURL,
+		Environment:       environment,
+		Timeout:           c.httpClient.Timeout,
+		PerAttemptTimeout: c.perAttemptTimeout,
+		MaxAttempts:       c.maxAttempts,
+		RetryBaseDelay:    c.retryBaseDelay,
+		RetryMaxDelay:     c.retryMaxDelay,
+		RateLimit:         rateLimit,
+		RateLimitBurst:    rateLimitBurst,
+		MaxPageSize:       c.maxPageSize,
+		APIKeyPrefix:      redactKey(c.credentials().apiKey),
+	}
+}
+
+// redactKey keeps at most the first 7 characters, and never more than half,
+// of an api key, e.g. "ak_1234…"
+func redactKey(key string) string {
+	if key == "" {
+		return ""
+	}
+	n := 7
+	if half := len(key) / 2; half < n {
+		n = half
+	}
+	return key[:n] + "…"
+}
@@ -0,0 +1,26 @@
+package cryptomepay
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiagnostics(t *testing.T) {
+	client := NewClientWithOptions("ak_1234567890abcdef", "super_secret", WithTimeout(10*time.Second))
+
+	d := client.Diagnostics()
+	assert.Equal(t, Version, d.SDKVersion)
+	assert.Equal(t, runtime.Version(), d.GoVersion)
+	assert.Equal(t, "production", d.Environment)
+	assert.Equal(t, 10*time.Second, d.Timeout)
+	assert.Equal(t, "ak_1234…", d.APIKeyPrefix)
+	assert.NotContains(t, d.APIKeyPrefix, "890abcdef")
+
+	custom := NewClientWithOptions("ak_12", "s", WithBaseURL("https://staging.example.com"))
+	d = custom.Diagnostics()
+	assert.Equal(t, "custom", d.Environment)
+	assert.Equal(t, "ak…", d.APIKeyPrefix)
+}
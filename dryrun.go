@@ -0,0 +1,71 @@
+package cryptomepay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// PreparedRequest is a fully built and signed API request that has not
+// been sent
+type PreparedRequest struct {
+	// Request has the URL, headers and body the client would send, and can
+	// be sent as is. GetBody returns a fresh copy of the body once Body has
+	// been read.
+	Request *http.Request
+
+	// Body is the JSON request body
+	Body []byte
+
+	// SigningString is the string the default signer signs (see
+	// SigningString), and Signature the signature sent in the body
+	SigningString string
+	Signature     string
+}
+
+// BuildCreatePaymentRequest builds the request CreatePayment would send for
+// params, without sending it, for comparing against the gateway's
+// expectations when it answers ErrCodeSignatureVerifyFailed. Validation,
+// generated order ids and request options apply as in CreatePayment.
+//
+// Each call signs with a fresh timestamp and nonce. A bearer token from
+// Authenticate is used as is, without refreshing, and middleware is not
+// applied.
+func (c *Client) BuildCreatePaymentRequest(ctx context.Context, params *CreatePaymentParams, opts ...RequestOption) (*PreparedRequest, error) {
+	params, _, err := c.preparePayment(params)
+	if err != nil {
+		return nil, err
+	}
+	creds := c.credentials()
+	body, signed, err := c.paymentBody(creds, params)
+	if err != nil {
+		return nil, err
+	}
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	opts = append([]RequestOption{WithIdempotencyKey(orderIdempotencyKey(params)), withAPIKey(creds.apiKey)}, opts...)
+	ro, err := c.newRequestOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	credential := ro.apiKey
+	if token, _, enabled := c.auth.get(); enabled && !ro.staticAuth {
+		credential = token
+	}
+
+	req, err := c.newHTTPRequest(ctx, &ro, "POST", ro.baseURL+"/order/create-transaction", jsonBody, credential)
+	if err != nil {
+		return nil, err
+	}
+	return &PreparedRequest{
+		Request:       req,
+		Body:          jsonBody,
+		SigningString: SigningString(signed),
+		Signature:     body["signature"].(string),
+	}, nil
+}
@@ -0,0 +1,76 @@
+package cryptomepay
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildCreatePaymentRequest(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(`{"status_code":200,"data":{"trade_id":"CP1"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+	params := &CreatePaymentParams{OrderID: "ORDER_001", Amount: 100, NotifyURL: "https://example.com/webhook"}
+
+	prepared, err := client.BuildCreatePaymentRequest(context.Background(), params, WithRequestHeader("X-Tenant", "t1"))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, calls, "a prepared request must not be sent")
+
+	req := prepared.Request
+	assert.Equal(t, "POST", req.Method)
+	assert.Equal(t, server.URL+"/order/create-transaction", req.URL.String())
+	assert.Equal(t, "Bearer sk_test_key", req.Header.Get("Authorization"))
+	assert.Equal(t, "order-ORDER_001", req.Header.Get("Idempotency-Key"))
+	assert.Equal(t, "t1", req.Header.Get("X-Tenant"))
+	assert.NotEmpty(t, req.Header.Get(ClientRequestIDHeader))
+
+	// The signature covers exactly the signing string
+	assert.True(t, strings.Contains(prepared.SigningString, "order_id=ORDER_001"))
+	assert.Equal(t, signHMAC("test_secret", prepared.SigningString), prepared.Signature)
+
+	var body map[string]interface{}
+	assert.NoError(t, json.Unmarshal(prepared.Body, &body))
+	assert.Equal(t, prepared.Signature, body["signature"])
+	assert.Equal(t, 100.0, body["amount"])
+
+	// GetBody returns a fresh copy after Body was read
+	read, err := io.ReadAll(req.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, prepared.Body, read)
+	again, err := req.GetBody()
+	assert.NoError(t, err)
+	read, err = io.ReadAll(again)
+	assert.NoError(t, err)
+	assert.Equal(t, prepared.Body, read)
+
+	req.Body, _ = req.GetBody()
+	resp, err := http.DefaultClient.Do(req)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	assert.Equal(t, 1, calls)
+}
+
+func TestBuildCreatePaymentRequestValidates(t *testing.T) {
+	client := NewClient("sk_test_key", "test_secret")
+
+	_, err := client.BuildCreatePaymentRequest(context.Background(), &CreatePaymentParams{OrderID: "ORDER_001"})
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+
+	params := &CreatePaymentParams{OrderID: "ORDER_001", Amount: 1, NotifyURL: "https://example.com/webhook"}
+	_, err = client.BuildCreatePaymentRequest(context.Background(), params, WithRequestBaseURL("not a url"))
+	assert.ErrorAs(t, err, &validationErr)
+}
@@ -0,0 +1,59 @@
+package cryptomepay
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Environment variables read by NewClientFromEnv
+const (
+	EnvAPIKey    = "CRYPTOME_API_KEY"
+	EnvAPISecret = "CRYPTOME_API_SECRET"
+	EnvBaseURL   = "CRYPTOME_BASE_URL"
+	EnvName      = "CRYPTOME_ENV"
+)
+
+// NewClientFromEnv creates a client from the CRYPTOME_* environment
+// variables, then applies opts.
+//
+// CRYPTOME_API_KEY and CRYPTOME_API_SECRET are required and checked as by
+// NewClientChecked. CRYPTOME_BASE_URL, when set, must be an absolute
+// http(s) URL and takes precedence over CRYPTOME_ENV. CRYPTOME_ENV may be
+// "production" (the default), "sandbox" or "staging"; the gateway publishes
+// no fixed URL for the last two, so they require CRYPTOME_BASE_URL.
+func NewClientFromEnv(opts ...Option) (*Client, error) {
+	apiKey := os.Getenv(EnvAPIKey)
+	apiSecret := os.Getenv(EnvAPISecret)
+	if apiKey == "" || apiSecret == "" {
+		return nil, fmt.Errorf("cryptomepay: %s and %s must be set", EnvAPIKey, EnvAPISecret)
+	}
+
+	baseURL, err := baseURLFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClientChecked(apiKey, apiSecret, append([]Option{WithBaseURL(baseURL)}, opts...)...)
+}
+
+// baseURLFromEnv resolves the base URL from CRYPTOME_BASE_URL and CRYPTOME_ENV
+func baseURLFromEnv() (string, error) {
+	env := strings.ToLower(strings.TrimSpace(os.Getenv(EnvName)))
+	switch env {
+	case "", "production", "sandbox", "staging":
+	default:
+		return "", fmt.Errorf("cryptomepay: unknown %s %q, want production, sandbox or staging", EnvName, env)
+	}
+
+	if baseURL := os.Getenv(EnvBaseURL); baseURL != "" {
+		if err := validateHTTPURL(EnvBaseURL, baseURL); err != nil {
+			return "", err
+		}
+		return baseURL, nil
+	}
+	if env == "sandbox" || env == "staging" {
+		return "", fmt.Errorf("cryptomepay: %s=%s requires %s", EnvName, env, EnvBaseURL)
+	}
+	return ProductionURL, nil
+}
@@ -0,0 +1,73 @@
+package cryptomepay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewClientFromEnv(t *testing.T) {
+	t.Setenv(EnvAPIKey, "sk_test_key")
+	t.Setenv(EnvAPISecret, "test_secret")
+	t.Setenv(EnvBaseURL, "")
+	t.Setenv(EnvName, "")
+
+	client, err := NewClientFromEnv(WithTimeout(5 * time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, "sk_test_key", client.apiKey)
+	assert.Equal(t, "test_secret", client.apiSecret)
+	assert.Equal(t, ProductionURL, client.baseURL)
+	assert.Equal(t, 5*time.Second, client.httpClient.Timeout)
+
+	t.Setenv(EnvBaseURL, "https://staging.example.com/api/v1/")
+	client, err = NewClientFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, "https://staging.example.com/api/v1", client.baseURL)
+}
+
+func TestNewClientFromEnvEnvironment(t *testing.T) {
+	t.Setenv(EnvAPIKey, "sk_test_key")
+	t.Setenv(EnvAPISecret, "test_secret")
+	t.Setenv(EnvBaseURL, "")
+
+	t.Setenv(EnvName, "Production")
+	client, err := NewClientFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, ProductionURL, client.baseURL)
+
+	// Sandbox and staging have no built-in URL
+	t.Setenv(EnvName, "sandbox")
+	_, err = NewClientFromEnv()
+	assert.ErrorContains(t, err, EnvBaseURL)
+
+	t.Setenv(EnvBaseURL, "https://sandbox.example.com")
+	client, err = NewClientFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, "https://sandbox.example.com", client.baseURL)
+
+	t.Setenv(EnvName, "dev")
+	_, err = NewClientFromEnv()
+	assert.ErrorContains(t, err, "unknown "+EnvName)
+}
+
+func TestNewClientFromEnvErrors(t *testing.T) {
+	t.Setenv(EnvName, "")
+	t.Setenv(EnvBaseURL, "")
+
+	t.Setenv(EnvAPIKey, "")
+	t.Setenv(EnvAPISecret, "test_secret")
+	_, err := NewClientFromEnv()
+	assert.ErrorContains(t, err, EnvAPIKey)
+
+	t.Setenv(EnvAPIKey, "sk_test_key")
+	t.Setenv(EnvAPISecret, "")
+	_, err = NewClientFromEnv()
+	assert.ErrorContains(t, err, EnvAPISecret)
+
+	t.Setenv(EnvAPISecret, "test_secret")
+	t.Setenv(EnvBaseURL, "api.example.com")
+	_, err = NewClientFromEnv()
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+}
@@ -1,6 +1,12 @@
 package cryptomepay
 
-import "fmt"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
 
 // Error codes
 const (
@@ -28,21 +34,132 @@ const (
 	ErrCodeBurstLimitExceeded = 50002
 )
 
+var (
+	// ErrInvalidSignature is returned when a webhook signature does not verify
+	ErrInvalidSignature = errors.New("cryptomepay: invalid webhook signature")
+
+	// ErrClientClosed is returned for requests started after Shutdown
+	ErrClientClosed = errors.New("cryptomepay: client is shut down")
+
+	// ErrOrderNotPaid is returned by RefundOrder and OrderData.PaidAtTime
+	// for an order that is not paid
+	ErrOrderNotPaid = errors.New("cryptomepay: order is not paid")
+
+	// ErrNoWebhookToResend is returned by ResendWebhook for an order that
+	// has not sent a webhook yet
+	ErrNoWebhookToResend = errors.New("cryptomepay: order has no webhook to resend")
+)
+
+// Errors matched by errors.Is on an *APIError with the corresponding
+// ErrCode, so callers need not compare status codes themselves:
+//
+//	if errors.Is(err, cryptomepay.ErrOrderExists) { ... }
+var (
+	ErrInvalidAPIKey            = errors.New("cryptomepay: invalid api key")
+	ErrSignatureVerifyFailed    = errors.New("cryptomepay: signature verification failed")
+	ErrAPIKeyExpired            = errors.New("cryptomepay: api key expired")
+	ErrIPNotWhitelisted         = errors.New("cryptomepay: IP address is not whitelisted")
+	ErrMerchantSuspended        = errors.New("cryptomepay: merchant suspended")
+	ErrInvalidOrderID           = errors.New("cryptomepay: invalid order id")
+	ErrOrderExists              = errors.New("cryptomepay: order already exists")
+	ErrNoAvailableWallet        = errors.New("cryptomepay: no available wallet")
+	ErrInvalidAmount            = errors.New("cryptomepay: invalid amount")
+	ErrAmountChannelUnavailable = errors.New("cryptomepay: amount channel unavailable")
+	ErrExchangeRate             = errors.New("cryptomepay: exchange rate error")
+	ErrOrderAlreadyPaid         = errors.New("cryptomepay: order already paid")
+	ErrOrderExpired             = errors.New("cryptomepay: order expired")
+	ErrInvalidChainType         = errors.New("cryptomepay: invalid chain type")
+	ErrChainUnavailable         = errors.New("cryptomepay: chain unavailable")
+	ErrChainMonitoringDelay     = errors.New("cryptomepay: chain monitoring delayed")
+	ErrRateLimitExceeded        = errors.New("cryptomepay: rate limit exceeded")
+	ErrBurstLimitExceeded       = errors.New("cryptomepay: burst limit exceeded")
+
+	// ErrOrderNotFound is also returned when a query succeeds without
+	// order data
+	ErrOrderNotFound = errors.New("cryptomepay: order not found")
+)
+
+// codeErrors maps error codes to the errors APIError.Is matches
+var codeErrors = map[int]error{
+	ErrCodeInvalidAPIKey:         ErrInvalidAPIKey,
+	ErrCodeSignatureVerifyFailed: ErrSignatureVerifyFailed,
+	ErrCodeAPIKeyExpired:         ErrAPIKeyExpired,
+	ErrCodeIPNotWhitelisted:      ErrIPNotWhitelisted,
+	ErrCodeMerchantSuspended:     ErrMerchantSuspended,
+	ErrCodeInvalidOrderID:        ErrInvalidOrderID,
+	ErrCodeOrderExists:           ErrOrderExists,
+	ErrCodeNoAvailableWallet:     ErrNoAvailableWallet,
+	ErrCodeInvalidAmount:         ErrInvalidAmount,
+	ErrCodeAmountChannelUnavail:  ErrAmountChannelUnavailable,
+	ErrCodeExchangeRateError:     ErrExchangeRate,
+	ErrCodeOrderAlreadyPaid:      ErrOrderAlreadyPaid,
+	ErrCodeOrderNotFound:         ErrOrderNotFound,
+	ErrCodeOrderExpired:          ErrOrderExpired,
+	ErrCodeInvalidChainType:      ErrInvalidChainType,
+	ErrCodeChainUnavailable:      ErrChainUnavailable,
+	ErrCodeChainMonitoringDelay:  ErrChainMonitoringDelay,
+	ErrCodeRateLimitExceeded:     ErrRateLimitExceeded,
+	ErrCodeBurstLimitExceeded:    ErrBurstLimitExceeded,
+}
+
+// FieldError describes a problem with a single request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ErrorResponse is the body the gateway returns for failed requests.
+//
+// It shares status_code, message and request_id with the success envelope
+// and may add per-field details under errors.
+type ErrorResponse struct {
+	StatusCode int          `json:"status_code"`
+	Message    string       `json:"message"`
+	RequestID  string       `json:"request_id"`
+	Errors     []FieldError `json:"errors,omitempty"`
+}
+
 // APIError represents an API error response
 type APIError struct {
-	StatusCode int    `json:"status_code"`
-	Message    string `json:"message"`
-	RequestID  string `json:"request_id"`
+	StatusCode int          `json:"status_code"`
+	Message    string       `json:"message"`
+	RequestID  string       `json:"request_id"`
+	Errors     []FieldError `json:"errors,omitempty"`
+
+	// HTTPStatus is the HTTP status code of the response, if any
+	HTTPStatus int `json:"-"`
+
+	// RetryAfter is the delay the response's Retry-After header asked for,
+	// in seconds or as an HTTP date, and 0 when it sent none
+	RetryAfter time.Duration `json:"-"`
 }
 
 func (e *APIError) Error() string {
-	return fmt.Sprintf("cryptomepay: %s (code=%d, request_id=%s)", e.Message, e.StatusCode, e.RequestID)
+	msg := fmt.Sprintf("cryptomepay: %s (code=%d, request_id=%s)", e.Message, e.StatusCode, e.RequestID)
+	for _, fe := range e.Errors {
+		msg += fmt.Sprintf("; %s: %s", fe.Field, fe.Message)
+	}
+	return msg
 }
 
-// IsRetryable returns true if the error can be retried
+// IsRetryable returns true if the error can be retried: the gateway's
+// rate limit codes, and otherwise HTTP 429 and 5xx responses that carry no
+// business error code
 func (e *APIError) IsRetryable() bool {
-	// Rate limit and server errors are retryable
-	return e.StatusCode == 429 || e.StatusCode >= 500
+	switch {
+	case e.StatusCode == ErrCodeRateLimitExceeded || e.StatusCode == ErrCodeBurstLimitExceeded:
+		return true
+	case e.StatusCode >= 1000:
+		// Business codes such as ErrCodeOrderExists fail the same way again
+		return false
+	}
+
+	status := e.HTTPStatus
+	if status == 0 {
+		// Not from a response, so StatusCode is the only status there is
+		status = e.StatusCode
+	}
+	return status == http.StatusTooManyRequests || status >= 500
 }
 
 // IsAuthError returns true if the error is an authentication error
@@ -60,6 +177,14 @@ func (e *APIError) IsChainError() bool {
 	return e.StatusCode >= 20001 && e.StatusCode <= 20003
 }
 
+// Is reports whether target is the error for e's status code, so that
+// errors.Is(err, ErrOrderExists) matches an *APIError with
+// ErrCodeOrderExists anywhere in err's chain
+func (e *APIError) Is(target error) bool {
+	codeErr, ok := codeErrors[e.StatusCode]
+	return ok && codeErr == target
+}
+
 // NewAPIError creates a new API error from a response
 func NewAPIError(statusCode int, message, requestID string) *APIError {
 	return &APIError{
@@ -68,3 +193,72 @@ func NewAPIError(statusCode int, message, requestID string) *APIError {
 		RequestID:  requestID,
 	}
 }
+
+// ValidationError is returned when parameters fail client-side validation
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("cryptomepay: invalid %s: %s", e.Field, e.Message)
+}
+
+// DecodeError is returned when a successful HTTP response cannot be decoded,
+// for example an HTML maintenance page served with status 200 or a
+// truncated body. Failed HTTP responses (status 400 and above) are always
+// reported as an APIError, whatever their body.
+type DecodeError struct {
+	HTTPStatus  int
+	ContentType string
+
+	// Body is the start of the raw body, at most 512 bytes
+	Body string
+
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("cryptomepay: failed to unmarshal response (HTTP %d, Content-Type %q, body %q): %v", e.HTTPStatus, e.ContentType, e.Body, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// newAPIErrorFromBody builds an APIError from a failed HTTP response body.
+// Bodies that are not a valid ErrorResponse fall back to the HTTP status.
+func newAPIErrorFromBody(httpStatus int, body []byte) *APIError {
+	var errResp ErrorResponse
+	if err := json.Unmarshal(body, &errResp); err != nil {
+		errResp = ErrorResponse{}
+	}
+
+	apiErr := &APIError{
+		StatusCode: errResp.StatusCode,
+		Message:    errResp.Message,
+		RequestID:  errResp.RequestID,
+		Errors:     errResp.Errors,
+		HTTPStatus: httpStatus,
+	}
+	if apiErr.StatusCode == 0 {
+		apiErr.StatusCode = httpStatus
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(httpStatus)
+	}
+	return apiErr
+}
+
+// newAPIErrorFromEnvelope returns an APIError when a successful HTTP
+// response carries a status_code other than 200, and nil otherwise.
+// Responses without a status_code, or with status_code 0, are not errors.
+func newAPIErrorFromEnvelope(httpStatus int, body []byte) *APIError {
+	var envelope struct {
+		StatusCode *int `json:"status_code"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil || envelope.StatusCode == nil || *envelope.StatusCode == 0 || *envelope.StatusCode == 200 {
+		return nil
+	}
+	return newAPIErrorFromBody(httpStatus, body)
+}
@@ -0,0 +1,216 @@
+package cryptomepay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIErrorFromErrorBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{
+			"status_code": 10004,
+			"message": "invalid amount",
+			"request_id": "req_err_1",
+			"errors": [{"field": "amount", "message": "must be greater than 0"}]
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	resp, err := client.CreatePayment(&CreatePaymentParams{
+		OrderID:   "ORDER_001",
+		Amount:    0.001,
+		NotifyURL: "https://example.com/webhook",
+	})
+
+	var apiErr *APIError
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, ErrCodeInvalidAmount, apiErr.StatusCode)
+	assert.Equal(t, http.StatusBadRequest, apiErr.HTTPStatus)
+	assert.Equal(t, "req_err_1", apiErr.RequestID)
+	assert.Equal(t, []FieldError{{Field: "amount", Message: "must be greater than 0"}}, apiErr.Errors)
+	assert.True(t, apiErr.IsValidationError())
+	assert.Contains(t, apiErr.Error(), "amount: must be greater than 0")
+
+	// The envelope is still decoded for callers checking StatusCode
+	assert.Equal(t, ErrCodeInvalidAmount, resp.StatusCode)
+}
+
+func TestAPIErrorFromNonJSONBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("<html>Bad Gateway</html>"))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	_, err := client.GetMerchantInfo()
+
+	var apiErr *APIError
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+	assert.Equal(t, "Bad Gateway", apiErr.Message)
+	assert.True(t, apiErr.IsRetryable())
+}
+
+func TestAPIErrorFromNon200StatusCode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status_code": 10002, "message": "order exists", "request_id": "req_dup_1"}`))
+	}))
+	defer server.Close()
+
+	params := &CreatePaymentParams{OrderID: "ORDER_001", Amount: 1, NotifyURL: "https://example.com/webhook"}
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+	resp, err := client.CreatePayment(params)
+
+	var apiErr *APIError
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, ErrCodeOrderExists, apiErr.StatusCode)
+	assert.Equal(t, "order exists", apiErr.Message)
+	assert.Equal(t, "req_dup_1", apiErr.RequestID)
+	assert.Equal(t, http.StatusOK, apiErr.HTTPStatus)
+	assert.Equal(t, ErrCodeOrderExists, resp.StatusCode)
+	assert.ErrorIs(t, err, ErrOrderExists)
+	assert.False(t, errors.Is(err, ErrOrderNotFound))
+
+	// The previous behaviour is kept behind the option
+	client = NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL), WithErrorOnNon200(false))
+	resp, err = client.CreatePayment(params)
+	assert.NoError(t, err)
+	assert.Equal(t, ErrCodeOrderExists, resp.StatusCode)
+}
+
+func TestAPIErrorIs(t *testing.T) {
+	for code, want := range codeErrors {
+		err := error(NewAPIError(code, "failed", "req_1"))
+		assert.ErrorIs(t, err, want, "%d", code)
+		for _, other := range codeErrors {
+			if other != want {
+				assert.False(t, errors.Is(err, other), "%d matches %v", code, other)
+			}
+		}
+	}
+
+	// Codes without a sentinel match none
+	err := NewAPIError(http.StatusBadGateway, "Bad Gateway", "")
+	for _, sentinel := range codeErrors {
+		assert.False(t, errors.Is(err, sentinel))
+	}
+	assert.False(t, errors.Is(NewAPIError(ErrCodeOrderExists, "exists", ""), NewAPIError(ErrCodeOrderExists, "exists", "")))
+}
+
+func TestDecodeError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/maintenance/"):
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<html>down for maintenance</html>"))
+		case strings.HasPrefix(r.URL.Path, "/truncated/"):
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"status_code":200,"data":{"na`))
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte("<html>bad gateway</html>"))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	_, err := client.GetMerchantInfo(WithRequestBaseURL(server.URL + "/maintenance"))
+	var decodeErr *DecodeError
+	if assert.ErrorAs(t, err, &decodeErr) {
+		assert.Equal(t, http.StatusOK, decodeErr.HTTPStatus)
+		assert.Equal(t, "text/html; charset=utf-8", decodeErr.ContentType)
+		assert.Equal(t, "<html>down for maintenance</html>", decodeErr.Body)
+		assert.Error(t, decodeErr.Err)
+	}
+	var apiErr *APIError
+	assert.False(t, errors.As(err, &apiErr))
+
+	_, err = client.GetMerchantInfo(WithRequestBaseURL(server.URL + "/truncated"))
+	if assert.ErrorAs(t, err, &decodeErr) {
+		assert.Equal(t, "application/json", decodeErr.ContentType)
+	}
+
+	// Failed HTTP statuses stay API errors whatever the body
+	_, err = client.GetMerchantInfo()
+	assert.False(t, errors.As(err, &decodeErr))
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, http.StatusBadGateway, apiErr.HTTPStatus)
+		assert.True(t, apiErr.IsRetryable())
+	}
+}
+
+func TestErrorChainAfterCancel(t *testing.T) {
+	started := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/hang/") {
+			started <- struct{}{}
+			<-r.Context().Done()
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"status_code":503,"message":"try again","request_id":"req_503"}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL), WithRetry(3, time.Minute))
+
+	// Canceled while the request is in flight
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	_, err := client.QueryPayment(ctx, QueryParams{TradeID: "CP1"}, WithRequestBaseURL(server.URL+"/hang"))
+	assert.ErrorIs(t, err, context.Canceled)
+	var reqErr *RequestError
+	assert.ErrorAs(t, err, &reqErr)
+
+	// Canceled while waiting to retry a failed attempt
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err = client.QueryPayment(ctx, QueryParams{TradeID: "CP1"})
+	assert.ErrorIs(t, err, context.Canceled)
+	var apiErr *APIError
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, http.StatusServiceUnavailable, apiErr.HTTPStatus)
+		assert.Equal(t, "req_503", apiErr.RequestID)
+	}
+	assert.ErrorAs(t, err, &reqErr)
+}
+
+func TestAPIErrorIsRetryable(t *testing.T) {
+	tests := []struct {
+		err  *APIError
+		want bool
+	}{
+		{&APIError{StatusCode: ErrCodeRateLimitExceeded, HTTPStatus: http.StatusOK}, true},
+		{&APIError{StatusCode: ErrCodeBurstLimitExceeded, HTTPStatus: http.StatusTooManyRequests}, true},
+		{&APIError{StatusCode: http.StatusServiceUnavailable, HTTPStatus: http.StatusServiceUnavailable}, true},
+		{&APIError{StatusCode: http.StatusTooManyRequests}, true},
+		{&APIError{StatusCode: ErrCodeOrderExists, HTTPStatus: http.StatusOK}, false},
+		{&APIError{StatusCode: ErrCodeOrderNotFound, HTTPStatus: http.StatusInternalServerError}, false},
+		{&APIError{StatusCode: ErrCodeInvalidAPIKey, HTTPStatus: http.StatusUnauthorized}, false},
+		{&APIError{StatusCode: http.StatusBadRequest, HTTPStatus: http.StatusBadRequest}, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.err.IsRetryable(), "code %d, HTTP %d", tt.err.StatusCode, tt.err.HTTPStatus)
+	}
+}
@@ -0,0 +1,143 @@
+package cryptomepay
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ExportFormat selects the output of ExportOrders
+type ExportFormat int
+
+// Export formats
+const (
+	// ExportCSV writes a header row and one row per order, with the
+	// columns in ExportColumns
+	ExportCSV ExportFormat = iota
+
+	// ExportJSONLines writes one OrderData JSON object per line
+	ExportJSONLines
+)
+
+// ExportColumns are the CSV columns written by ExportOrders, in order.
+// Amounts have 2 decimals and actual amounts 4, and status is the status
+// name ("pending", "paid", "expired"). Columns are only ever added at the
+// end.
+var ExportColumns = []string{
+	"trade_id",
+	"order_id",
+	"amount",
+	"actual_amount",
+	"token",
+	"chain_type",
+	"status",
+	"block_transaction_id",
+	"created_at",
+	"paid_at",
+	"exchange_rate",
+	"expiration_time",
+}
+
+// exportFlushRows is how many rows ExportOrders writes between flushes
+const exportFlushRows = 100
+
+// ExportOrders writes every order matching params to w in format, fetching
+// pages with ListOrdersAll so only one page is held in memory. Output is
+// flushed every 100 rows and at the end. If ctx is done or a page fails,
+// the export stops with that error, leaving the rows written so far.
+func (c *Client) ExportOrders(ctx context.Context, w io.Writer, params *ListOrdersParams, format ExportFormat, opts ...RequestOption) error {
+	var out exportWriter
+	switch format {
+	case ExportCSV:
+		out = &csvExporter{w: csv.NewWriter(w)}
+	case ExportJSONLines:
+		buf := bufio.NewWriter(w)
+		out = &jsonLinesExporter{buf: buf, enc: json.NewEncoder(buf)}
+	default:
+		return &ValidationError{Field: "format", Message: fmt.Sprintf("unknown export format %d", format)}
+	}
+
+	if err := out.header(); err != nil {
+		return err
+	}
+
+	rows := 0
+	it := c.ListOrdersAll(ctx, params, opts...)
+	for it.Next() {
+		if err := ctx.Err(); err != nil {
+			out.flush()
+			return err
+		}
+		order := it.Order()
+		if err := out.write(&order); err != nil {
+			return err
+		}
+		if rows++; rows%exportFlushRows == 0 {
+			if err := out.flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := out.flush(); err != nil {
+		return err
+	}
+	return it.Err()
+}
+
+// exportWriter writes orders in one ExportFormat
+type exportWriter interface {
+	header() error
+	write(order *OrderData) error
+	flush() error
+}
+
+type csvExporter struct {
+	w *csv.Writer
+}
+
+func (e *csvExporter) header() error {
+	return e.w.Write(ExportColumns)
+}
+
+func (e *csvExporter) write(o *OrderData) error {
+	return e.w.Write([]string{
+		o.TradeID,
+		o.OrderID,
+		formatAmount(o.Amount),
+		formatActualAmount(o.ActualAmount),
+		o.Token,
+		string(o.ChainType),
+		o.Status.String(),
+		o.BlockTransactionID,
+		o.CreatedAt,
+		o.PaidAt,
+		strconv.FormatFloat(o.ExchangeRate, 'f', -1, 64),
+		strconv.FormatInt(o.ExpirationTime, 10),
+	})
+}
+
+func (e *csvExporter) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type jsonLinesExporter struct {
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+func (e *jsonLinesExporter) header() error {
+	return nil
+}
+
+func (e *jsonLinesExporter) write(o *OrderData) error {
+	return e.enc.Encode(o)
+}
+
+func (e *jsonLinesExporter) flush() error {
+	return e.buf.Flush()
+}
@@ -0,0 +1,209 @@
+package cryptomepay
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// exportServer serves total paid orders in pages, calling onPage before
+// answering each one
+func exportServer(total int, onPage func(page int)) *httptest.Server {
+	return httptest.NewServer(exportHandler(total, onPage))
+}
+
+// gzipExportServer is exportServer with every page gzip-encoded
+func gzipExportServer(total int, onPage func(page int)) *httptest.Server {
+	handler := exportHandler(total, onPage)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			http.Error(w, "gzip not accepted", http.StatusNotAcceptable)
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		defer zw.Close()
+		handler(gzipResponseWriter{w, zw}, r)
+	}))
+}
+
+// gzipResponseWriter sends the body written to it through zw
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	zw *gzip.Writer
+}
+
+func (w gzipResponseWriter) Write(p []byte) (int, error) { return w.zw.Write(p) }
+
+func exportHandler(total int, onPage func(page int)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+		if onPage != nil {
+			onPage(page)
+		}
+
+		var list []OrderData
+		for i := (page - 1) * size; i < page*size && i < total; i++ {
+			list = append(list, OrderData{
+				TradeID:      fmt.Sprintf("CP%d", i+1),
+				OrderID:      fmt.Sprintf("ORDER_%d", i+1),
+				Amount:       100,
+				ActualAmount: 14.5,
+				ChainType:    ChainBSC,
+				Status:       StatusPaid,
+				CreatedAt:    "2025-12-01 10:00:00",
+				PaidAt:       "2025-12-01 10:05:00",
+			})
+		}
+		json.NewEncoder(w).Encode(OrderListResponse{StatusCode: 200, Data: &OrderListData{List: list, Total: total}})
+	}
+}
+
+func TestExportOrdersCSV(t *testing.T) {
+	server := exportServer(5, nil)
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	var buf bytes.Buffer
+	err := client.ExportOrders(context.Background(), &buf, &ListOrdersParams{PageSize: 2, Status: StatusPaid}, ExportCSV)
+	assert.NoError(t, err)
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	if assert.Len(t, rows, 6) {
+		assert.Equal(t, ExportColumns, rows[0])
+		assert.Equal(t, []string{"CP1", "ORDER_1", "100.00", "14.5000", "", "BSC", "paid", "",
+			"2025-12-01 10:00:00", "2025-12-01 10:05:00", "0", "0"}, rows[1])
+		assert.Equal(t, "CP5", rows[5][0])
+	}
+}
+
+func TestExportOrdersJSONLines(t *testing.T) {
+	server := exportServer(3, nil)
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	var buf bytes.Buffer
+	err := client.ExportOrders(context.Background(), &buf, &ListOrdersParams{PageSize: 2}, ExportJSONLines)
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if assert.Len(t, lines, 3) {
+		var order OrderData
+		assert.NoError(t, json.Unmarshal([]byte(lines[2]), &order))
+		assert.Equal(t, "CP3", order.TradeID)
+		assert.Equal(t, StatusPaid, order.Status)
+	}
+}
+
+func TestExportOrdersCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The context is canceled while the second page is being served
+	server := exportServer(10, func(page int) {
+		if page == 2 {
+			cancel()
+		}
+	})
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	var buf bytes.Buffer
+	err := client.ExportOrders(ctx, &buf, &ListOrdersParams{PageSize: 2}, ExportCSV)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// The header and the first page were written before stopping
+	rows, _ := csv.NewReader(&buf).ReadAll()
+	assert.Len(t, rows, 3)
+}
+
+func TestExportOrdersUnknownFormat(t *testing.T) {
+	client := NewClient("sk_test_key", "test_secret")
+
+	var buf bytes.Buffer
+	err := client.ExportOrders(context.Background(), &buf, &ListOrdersParams{}, ExportFormat(9))
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Zero(t, buf.Len())
+}
+
+// exportPeakHeap exports pages gzip-encoded pages of 100 orders and returns
+// the largest live heap seen while the pages are served
+func exportPeakHeap(t *testing.T, pages int) uint64 {
+	var mu sync.Mutex
+	var peak uint64
+	server := gzipExportServer(pages*100, func(int) {
+		var stats runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&stats)
+		mu.Lock()
+		if stats.HeapAlloc > peak {
+			peak = stats.HeapAlloc
+		}
+		mu.Unlock()
+	})
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+	err := client.ExportOrders(context.Background(), io.Discard, &ListOrdersParams{PageSize: 100, Status: StatusPaid}, ExportJSONLines)
+	assert.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	return peak
+}
+
+func TestExportOrdersMemory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("exports thousands of orders")
+	}
+
+	// Exported pages are released as the export goes, so the live heap does
+	// not grow with the page count; holding 30,000 orders would add
+	// megabytes
+	small := exportPeakHeap(t, 10)
+	large := exportPeakHeap(t, 300)
+	assert.Less(t, large, small+1<<20, "peak heap %d bytes for 10 pages, %d for 300", small, large)
+}
+
+func BenchmarkExportOrdersGzip(b *testing.B) {
+	for _, pages := range []int{10, 100} {
+		b.Run(fmt.Sprintf("pages=%d", pages), func(b *testing.B) {
+			server := gzipExportServer(pages*100, nil)
+			defer server.Close()
+
+			client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+			// B/order stays the same whatever the page count
+			var before, after runtime.MemStats
+			b.ReportAllocs()
+			runtime.ReadMemStats(&before)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := client.ExportOrders(context.Background(), io.Discard, &ListOrdersParams{PageSize: 100, Status: StatusPaid}, ExportCSV); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.StopTimer()
+			runtime.ReadMemStats(&after)
+			b.ReportMetric(float64(after.TotalAlloc-before.TotalAlloc)/float64(b.N*pages*100), "B/order")
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package cryptomepay
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Fingerprint returns a stable hex SHA-256 over the fields that identify an
+// order state: trade_id, status, amount (2 decimals) and actual_amount
+// (4 decimals). Volatile fields such as timestamps and signatures are
+// excluded, so the same order state always yields the same fingerprint and
+// an OrderData and WebhookPayload describing it match.
+func (o *OrderData) Fingerprint() string {
+	return fingerprint(o.TradeID, o.Status, o.Amount, o.ActualAmount)
+}
+
+// Fingerprint returns the same value as OrderData.Fingerprint for the
+// order state carried by the webhook.
+func (p *WebhookPayload) Fingerprint() string {
+	return fingerprint(p.TradeID, p.Status, p.Amount, p.ActualAmount)
+}
+
+func fingerprint(tradeID string, status PaymentStatus, amount, actualAmount float64) string {
+	s := fmt.Sprintf("trade_id=%s&status=%d&amount=%s&actual_amount=%s",
+		tradeID, status, formatAmount(amount), formatActualAmount(actualAmount))
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
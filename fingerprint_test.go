@@ -0,0 +1,19 @@
+package cryptomepay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprint(t *testing.T) {
+	order := &OrderData{TradeID: "CP1", Status: StatusPaid, Amount: 100, ActualAmount: 15.625, PaidAt: "2025-12-01 10:00:00"}
+	payload := &WebhookPayload{TradeID: "CP1", Status: StatusPaid, Amount: 100, ActualAmount: 15.625, Timestamp: 1700000000, Signature: "abc"}
+
+	// Pinned so the value stays stable across SDK versions
+	assert.Equal(t, "3586a7413f75715a47f2a7162c7fc17ac3785de1227d01e710a725203b8e1867", order.Fingerprint())
+	assert.Equal(t, order.Fingerprint(), payload.Fingerprint())
+
+	payload.Status = StatusExpired
+	assert.NotEqual(t, order.Fingerprint(), payload.Fingerprint())
+}
@@ -0,0 +1,112 @@
+package cryptomepay
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FixtureKind identifies what a fixture represents
+type FixtureKind string
+
+// Fixture kinds
+const (
+	FixtureRequest FixtureKind = "request"
+	FixtureWebhook FixtureKind = "webhook"
+)
+
+// FixtureCase describes one set of fields to sign
+type FixtureCase struct {
+	Name   string            `json:"name"`
+	Kind   FixtureKind       `json:"kind"`
+	Params map[string]string `json:"params"`
+}
+
+// Fixture is a signed test vector that can be written out as JSON and
+// replayed against other SDKs or server implementations.
+type Fixture struct {
+	Name          string            `json:"name"`
+	Kind          FixtureKind       `json:"kind"`
+	SigningString string            `json:"signing_string"`
+	Signature     string            `json:"signature"`
+	Body          map[string]string `json:"body"`
+}
+
+// GenerateFixtures signs each case with secret using SigningString and
+// returns the signed bodies in input order.
+func GenerateFixtures(secret string, cases []FixtureCase) ([]Fixture, error) {
+	if secret == "" {
+		return nil, errors.New("cryptomepay: fixture secret is required")
+	}
+
+	fixtures := make([]Fixture, 0, len(cases))
+	seen := make(map[string]bool, len(cases))
+
+	for i, fc := range cases {
+		if fc.Name == "" {
+			return nil, fmt.Errorf("cryptomepay: fixture case %d has no name", i)
+		}
+		if seen[fc.Name] {
+			return nil, fmt.Errorf("cryptomepay: duplicate fixture case %q", fc.Name)
+		}
+		seen[fc.Name] = true
+
+		if fc.Kind != FixtureRequest && fc.Kind != FixtureWebhook {
+			return nil, fmt.Errorf("cryptomepay: fixture case %q has unknown kind %q", fc.Name, fc.Kind)
+		}
+
+		signingString := SigningString(fc.Params)
+		signature := signHMAC(secret, signingString)
+
+		body := make(map[string]string, len(fc.Params)+1)
+		for k, v := range fc.Params {
+			if k != "signature" {
+				body[k] = v
+			}
+		}
+		body["signature"] = signature
+
+		fixtures = append(fixtures, Fixture{
+			Name:          fc.Name,
+			Kind:          fc.Kind,
+			SigningString: signingString,
+			Signature:     signature,
+			Body:          body,
+		})
+	}
+
+	return fixtures, nil
+}
+
+// SignatureMismatchError reports a signature that does not match a known
+// vector. It carries the canonical signing string but never the secret.
+type SignatureMismatchError struct {
+	SigningString string
+	Expected      string
+	Computed      string
+}
+
+func (e *SignatureMismatchError) Error() string {
+	return fmt.Sprintf("cryptomepay: signature mismatch: expected %s, computed %s over %q",
+		e.Expected, e.Computed, e.SigningString)
+}
+
+// VerifyAgainstKnownVector recomputes the signature of params with secret and
+// compares it in constant time with expectedSig. A *SignatureMismatchError
+// describing the canonical string is returned on mismatch.
+func VerifyAgainstKnownVector(secret string, params map[string]string, expectedSig string) error {
+	if secret == "" {
+		return errors.New("cryptomepay: secret is required")
+	}
+
+	signingString := SigningString(params)
+	computed := signHMAC(secret, signingString)
+	if !hmacEqual(computed, strings.ToLower(expectedSig)) {
+		return &SignatureMismatchError{
+			SigningString: signingString,
+			Expected:      expectedSig,
+			Computed:      computed,
+		}
+	}
+	return nil
+}
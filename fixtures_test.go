@@ -0,0 +1,76 @@
+package cryptomepay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSigningString(t *testing.T) {
+	params := map[string]string{
+		"order_id":  "ORDER_001",
+		"amount":    "100.00",
+		"empty":     "",
+		"signature": "ignored",
+	}
+
+	assert.Equal(t, "amount=100.00&order_id=ORDER_001", SigningString(params))
+}
+
+func TestGenerateFixtures(t *testing.T) {
+	client := NewClient("sk_test_key", "test_secret")
+
+	params := map[string]string{
+		"trade_id": "CP123",
+		"status":   "2",
+	}
+
+	fixtures, err := GenerateFixtures("test_secret", []FixtureCase{
+		{Name: "ping", Kind: FixtureWebhook, Params: params},
+		{Name: "create", Kind: FixtureRequest, Params: map[string]string{"order_id": "O1"}},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, fixtures, 2)
+	assert.Equal(t, "status=2&trade_id=CP123", fixtures[0].SigningString)
+	assert.Equal(t, client.calculateSignature(params), fixtures[0].Signature)
+	assert.Equal(t, fixtures[0].Signature, fixtures[0].Body["signature"])
+	assert.Equal(t, "create", fixtures[1].Name)
+}
+
+func TestGenerateFixturesErrors(t *testing.T) {
+	_, err := GenerateFixtures("", nil)
+	assert.Error(t, err)
+
+	_, err = GenerateFixtures("secret", []FixtureCase{{Kind: FixtureRequest}})
+	assert.Error(t, err)
+
+	_, err = GenerateFixtures("secret", []FixtureCase{{Name: "a", Kind: "other"}})
+	assert.Error(t, err)
+
+	_, err = GenerateFixtures("secret", []FixtureCase{
+		{Name: "a", Kind: FixtureRequest},
+		{Name: "a", Kind: FixtureRequest},
+	})
+	assert.Error(t, err)
+}
+
+func TestVerifyAgainstKnownVector(t *testing.T) {
+	params := map[string]string{
+		"order_id": "ORDER_001",
+		"amount":   "100.00",
+	}
+	client := NewClient("sk_test_key", "test_secret")
+	sig := client.calculateSignature(params)
+
+	assert.NoError(t, VerifyAgainstKnownVector("test_secret", params, sig))
+
+	err := VerifyAgainstKnownVector("wrong_secret", params, sig)
+	var mismatch *SignatureMismatchError
+	assert.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, "amount=100.00&order_id=ORDER_001", mismatch.SigningString)
+	assert.Equal(t, sig, mismatch.Expected)
+	assert.NotContains(t, err.Error(), "wrong_secret")
+
+	assert.Error(t, VerifyAgainstKnownVector("", params, sig))
+}
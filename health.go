@@ -0,0 +1,39 @@
+package cryptomepay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// IsIPNotWhitelisted returns true if the gateway rejected the caller's IP
+func (e *APIError) IsIPNotWhitelisted() bool {
+	return e.StatusCode == ErrCodeIPNotWhitelisted
+}
+
+// Ping checks that the gateway is reachable and accepts the client's
+// credentials from this host, by fetching the merchant profile. It bypasses
+// WithMerchantCache and is meant for startup checks and readiness probes.
+//
+// A rejected IP address fails with an error matching ErrIPNotWhitelisted
+// that names the likely fix; other authentication failures say so. In both
+// cases errors.As still finds the *APIError. A non-200 status_code fails
+// even with WithErrorOnNon200(false).
+func (c *Client) Ping(ctx context.Context, opts ...RequestOption) error {
+	resp, err := c.fetchMerchantInfo(ctx, opts...)
+	if err == nil && resp.StatusCode != 200 {
+		err = NewAPIError(resp.StatusCode, resp.Message, resp.RequestID)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	switch {
+	case apiErr.IsIPNotWhitelisted():
+		return fmt.Errorf("%w: add this host's outbound IP address to the merchant's allowlist: %w", ErrIPNotWhitelisted, err)
+	case apiErr.IsAuthError():
+		return fmt.Errorf("cryptomepay: authentication failed: %w", err)
+	}
+	return err
+}
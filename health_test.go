@@ -0,0 +1,82 @@
+package cryptomepay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPing(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		assert.Equal(t, "/merchant/info", r.URL.Path)
+		assert.Equal(t, "Bearer sk_test_key", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"status_code":200,"data":{"name":"Shop"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL), WithMerchantCache(time.Minute))
+
+	// Each ping reaches the gateway, even with the merchant cache
+	assert.NoError(t, client.Ping(context.Background()))
+	assert.NoError(t, client.Ping(context.Background()))
+	assert.Equal(t, 2, calls)
+}
+
+func TestPingIPNotWhitelisted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"status_code":1004,"message":"IP not whitelisted","request_id":"req_ip"}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	err := client.Ping(context.Background())
+	assert.ErrorIs(t, err, ErrIPNotWhitelisted)
+	assert.Contains(t, err.Error(), "outbound IP")
+
+	var apiErr *APIError
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.True(t, apiErr.IsIPNotWhitelisted())
+		assert.Equal(t, "req_ip", apiErr.RequestID)
+	}
+}
+
+func TestPingErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		opts   []Option
+		auth   bool
+	}{
+		{"invalid key", http.StatusUnauthorized, `{"status_code":1001,"message":"invalid api key"}`, nil, true},
+		{"suspended", http.StatusOK, `{"status_code":1005,"message":"merchant suspended"}`, []Option{WithErrorOnNon200(false)}, true},
+		{"server error", http.StatusBadGateway, `bad gateway`, []Option{WithRetry(1, 0)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClientWithOptions("sk_test_key", "test_secret", append([]Option{WithBaseURL(server.URL)}, tt.opts...)...)
+
+			err := client.Ping(context.Background())
+			var apiErr *APIError
+			assert.ErrorAs(t, err, &apiErr)
+			assert.False(t, errors.Is(err, ErrIPNotWhitelisted))
+			assert.Equal(t, tt.auth, strings.Contains(err.Error(), "authentication failed"))
+		})
+	}
+}
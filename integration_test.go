@@ -3,6 +3,7 @@
 package cryptomepay
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"testing"
@@ -19,22 +20,26 @@ import (
 //   CRYPTOME_API_KEY    - Your API key (ak_xxx)
 //   CRYPTOME_API_SECRET - Your API secret (sk_xxx)
 //   CRYPTOME_BASE_URL   - Optional, defaults to production URL
+//   CRYPTOME_ENV        - Optional, production, sandbox or staging
 
 func getTestClient(t *testing.T) *Client {
-	apiKey := os.Getenv("CRYPTOME_API_KEY")
-	apiSecret := os.Getenv("CRYPTOME_API_SECRET")
-	baseURL := os.Getenv("CRYPTOME_BASE_URL")
-
-	if apiKey == "" || apiSecret == "" {
-		t.Skip("CRYPTOME_API_KEY and CRYPTOME_API_SECRET environment variables required")
+	client, err := NewClientFromEnv()
+	if err != nil {
+		t.Skip(err)
 	}
+	return client
+}
 
-	opts := []Option{}
-	if baseURL != "" {
-		opts = append(opts, WithBaseURL(baseURL))
-	}
+// skipOnAPIError skips the test when the gateway rejects a call, which
+// usually means the test merchant has no wallet configured for the chain
+func skipOnAPIError(t *testing.T, err error) {
+	t.Helper()
 
-	return NewClientWithOptions(apiKey, apiSecret, opts...)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		t.Skipf("Skipping - gateway rejected the request: %s (code: %d)", apiErr.Message, apiErr.StatusCode)
+	}
+	require.NoError(t, err)
 }
 
 func TestIntegration_GetMerchantInfo(t *testing.T) {
@@ -43,7 +48,7 @@ func TestIntegration_GetMerchantInfo(t *testing.T) {
 	resp, err := client.GetMerchantInfo()
 	require.NoError(t, err)
 
-	fmt.Printf("Merchant Info Response: %+v\n", resp)
+	fmt.Printf("Merchant Info Response: %s\n", resp.DebugString())
 
 	assert.Equal(t, 200, resp.StatusCode)
 	assert.NotNil(t, resp.Data)
@@ -68,15 +73,9 @@ func TestIntegration_CreatePayment(t *testing.T) {
 		ChainType: ChainBSC,
 	})
 
-	require.NoError(t, err)
-
-	fmt.Printf("Create Payment Response: %+v\n", resp)
+	skipOnAPIError(t, err)
 
-	if resp.StatusCode != 200 {
-		t.Logf("Create payment failed: %s (code: %d)", resp.Message, resp.StatusCode)
-		// Don't fail - might be due to missing wallet configuration
-		t.Skip("Skipping - payment creation may require wallet configuration")
-	}
+	fmt.Printf("Create Payment Response: %s\n", resp.DebugString())
 
 	assert.Equal(t, 200, resp.StatusCode)
 	assert.NotNil(t, resp.Data)
@@ -110,14 +109,9 @@ func TestIntegration_CreatePaymentWithUUID(t *testing.T) {
 		ChainType: ChainTRC20,
 	})
 
-	require.NoError(t, err)
+	skipOnAPIError(t, err)
 
-	fmt.Printf("Create Payment (UUID) Response: %+v\n", resp)
-
-	if resp.StatusCode != 200 {
-		t.Logf("Create payment failed: %s (code: %d)", resp.Message, resp.StatusCode)
-		t.Skip("Skipping - payment creation may require wallet configuration")
-	}
+	fmt.Printf("Create Payment (UUID) Response: %s\n", resp.DebugString())
 
 	assert.Equal(t, 200, resp.StatusCode)
 	assert.Equal(t, orderID, resp.Data.OrderID)
@@ -141,14 +135,9 @@ func TestIntegration_CreatePaymentWithLongOrderID(t *testing.T) {
 		ChainType: ChainBSC,
 	})
 
-	require.NoError(t, err)
-
-	fmt.Printf("Create Payment (Long ID) Response: %+v\n", resp)
+	skipOnAPIError(t, err)
 
-	if resp.StatusCode != 200 {
-		t.Logf("Create payment failed: %s (code: %d)", resp.Message, resp.StatusCode)
-		t.Skip("Skipping - payment creation may require wallet configuration")
-	}
+	fmt.Printf("Create Payment (Long ID) Response: %s\n", resp.DebugString())
 
 	assert.Equal(t, 200, resp.StatusCode)
 	assert.Equal(t, orderID, resp.Data.OrderID)
@@ -169,17 +158,13 @@ func TestIntegration_QueryPaymentByOrderID(t *testing.T) {
 		ChainType: ChainBSC,
 	})
 
-	require.NoError(t, err)
-
-	if createResp.StatusCode != 200 {
-		t.Skip("Skipping - payment creation failed")
-	}
+	skipOnAPIError(t, err)
 
 	// Query by order ID
 	resp, err := client.QueryPaymentByOrderID(orderID)
 	require.NoError(t, err)
 
-	fmt.Printf("Query Payment Response: %+v\n", resp)
+	fmt.Printf("Query Payment Response: %s\n", resp.DebugString())
 
 	assert.Equal(t, 200, resp.StatusCode)
 	assert.NotNil(t, resp.Data)
@@ -205,11 +190,7 @@ func TestIntegration_QueryPaymentByTradeID(t *testing.T) {
 		ChainType: ChainBSC,
 	})
 
-	require.NoError(t, err)
-
-	if createResp.StatusCode != 200 {
-		t.Skip("Skipping - payment creation failed")
-	}
+	skipOnAPIError(t, err)
 
 	tradeID := createResp.Data.TradeID
 
@@ -217,7 +198,7 @@ func TestIntegration_QueryPaymentByTradeID(t *testing.T) {
 	resp, err := client.QueryPaymentByTradeID(tradeID)
 	require.NoError(t, err)
 
-	fmt.Printf("Query Payment by Trade ID Response: %+v\n", resp)
+	fmt.Printf("Query Payment by Trade ID Response: %s\n", resp.DebugString())
 
 	assert.Equal(t, 200, resp.StatusCode)
 	assert.NotNil(t, resp.Data)
@@ -238,7 +219,7 @@ func TestIntegration_ListOrders(t *testing.T) {
 
 	require.NoError(t, err)
 
-	fmt.Printf("List Orders Response: %+v\n", resp)
+	fmt.Printf("List Orders Response: %s\n", resp.DebugString())
 
 	assert.Equal(t, 200, resp.StatusCode)
 	assert.NotNil(t, resp.Data)
@@ -274,7 +255,7 @@ func TestIntegration_ListOrdersWithFilters(t *testing.T) {
 
 	require.NoError(t, err)
 
-	fmt.Printf("List Paid Orders Response: %+v\n", resp)
+	fmt.Printf("List Paid Orders Response: %s\n", resp.DebugString())
 
 	assert.Equal(t, 200, resp.StatusCode)
 
@@ -298,13 +279,13 @@ func TestIntegration_ListOrdersByChain(t *testing.T) {
 
 	require.NoError(t, err)
 
-	fmt.Printf("List BSC Orders Response: %+v\n", resp)
+	fmt.Printf("List BSC Orders Response: %s\n", resp.DebugString())
 
 	assert.Equal(t, 200, resp.StatusCode)
 
 	// All returned orders should be BSC
 	for _, order := range resp.Data.List {
-		assert.Equal(t, ChainBSC, order.ChainType)
+		assert.Equal(t, ChainType(ChainBSC), order.ChainType)
 	}
 
 	fmt.Printf("BSC Orders: %d\n", len(resp.Data.List))
@@ -313,10 +294,10 @@ func TestIntegration_ListOrdersByChain(t *testing.T) {
 func TestIntegration_MultipleChains(t *testing.T) {
 	client := getTestClient(t)
 
-	chains := []string{ChainTRC20, ChainBSC}
+	chains := []ChainType{ChainTRC20, ChainBSC}
 
 	for _, chain := range chains {
-		t.Run("Chain_"+chain, func(t *testing.T) {
+		t.Run("Chain_"+string(chain), func(t *testing.T) {
 			orderID := fmt.Sprintf("CHAIN_%s_%d", chain, time.Now().UnixNano())
 
 			resp, err := client.CreatePayment(&CreatePaymentParams{
@@ -326,12 +307,7 @@ func TestIntegration_MultipleChains(t *testing.T) {
 				ChainType: chain,
 			})
 
-			require.NoError(t, err)
-
-			if resp.StatusCode != 200 {
-				t.Logf("Chain %s not configured: %s", chain, resp.Message)
-				t.Skip("Chain not configured")
-			}
+			skipOnAPIError(t, err)
 
 			assert.Equal(t, 200, resp.StatusCode)
 			assert.Equal(t, chain, resp.Data.ChainType)
@@ -354,8 +330,8 @@ func TestIntegration_SignatureGeneration(t *testing.T) {
 		"nonce":      "abcdef123456",
 	}
 
-	sig1 := client.generateSignature(params)
-	sig2 := client.generateSignature(params)
+	sig1 := client.calculateSignature(params)
+	sig2 := client.calculateSignature(params)
 
 	assert.Equal(t, sig1, sig2, "Signature should be deterministic")
 	assert.Len(t, sig1, 64, "HMAC-SHA256 signature should be 64 hex characters")
@@ -385,11 +361,11 @@ func TestIntegration_WebhookSignatureVerification(t *testing.T) {
 		"amount":               fmt.Sprintf("%.2f", payload.Amount),
 		"actual_amount":        fmt.Sprintf("%.4f", payload.ActualAmount),
 		"token":                payload.Token,
-		"chain_type":           payload.ChainType,
+		"chain_type":           string(payload.ChainType),
 		"block_transaction_id": payload.BlockTransactionID,
 		"status":               fmt.Sprintf("%d", payload.Status),
 	}
-	payload.Signature = client.generateSignature(params)
+	payload.Signature = client.calculateSignature(params)
 
 	// Verify should pass
 	assert.True(t, client.VerifyWebhookSignature(payload), "Valid signature should verify")
@@ -408,17 +384,13 @@ func TestIntegration_ErrorHandling(t *testing.T) {
 	orderID := fmt.Sprintf("DUP_TEST_%d", time.Now().Unix()) // Use seconds for potential duplicate
 
 	// Create first order
-	resp1, err := client.CreatePayment(&CreatePaymentParams{
+	_, err := client.CreatePayment(&CreatePaymentParams{
 		OrderID:   orderID,
 		Amount:    1.00,
 		NotifyURL: "https://webhook.site/test-webhook",
 		ChainType: ChainBSC,
 	})
-	require.NoError(t, err)
-
-	if resp1.StatusCode != 200 {
-		t.Skip("First order creation failed")
-	}
+	skipOnAPIError(t, err)
 
 	// Try to create duplicate
 	resp2, err := client.CreatePayment(&CreatePaymentParams{
@@ -427,10 +399,11 @@ func TestIntegration_ErrorHandling(t *testing.T) {
 		NotifyURL: "https://webhook.site/test-webhook",
 		ChainType: ChainBSC,
 	})
-	require.NoError(t, err)
 
 	// Should get error for duplicate
-	assert.NotEqual(t, 200, resp2.StatusCode, "Duplicate order should fail")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr, "Duplicate order should fail")
+	assert.NotEqual(t, 200, resp2.StatusCode)
 
 	fmt.Printf("Duplicate order error: %s (code: %d)\n", resp2.Message, resp2.StatusCode)
 }
@@ -440,9 +413,10 @@ func TestIntegration_QueryNonExistentOrder(t *testing.T) {
 
 	// Query non-existent order
 	resp, err := client.QueryPaymentByOrderID("NON_EXISTENT_ORDER_12345")
-	require.NoError(t, err)
 
-	assert.NotEqual(t, 200, resp.StatusCode, "Non-existent order should return error")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr, "Non-existent order should return error")
+	assert.NotEqual(t, 200, resp.StatusCode)
 
 	fmt.Printf("Non-existent order error: %s (code: %d)\n", resp.Message, resp.StatusCode)
 }
@@ -489,6 +463,6 @@ func BenchmarkSignatureGeneration(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		client.generateSignature(params)
+		client.calculateSignature(params)
 	}
 }
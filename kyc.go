@@ -0,0 +1,288 @@
+package cryptomepay
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KYC statuses as reported in MerchantData.KYCStatus. The gateway may
+// report others, which WatchKYCStatus treats as not final.
+const (
+	KYCStatusPending  = "pending"
+	KYCStatusVerified = "verified"
+	KYCStatusRejected = "rejected"
+)
+
+// KYCLevel is a merchant verification level as reported in MerchantData.KYCLevel
+type KYCLevel int
+
+// KYC levels
+const (
+	KYCLevelNone     KYCLevel = 0
+	KYCLevelBasic    KYCLevel = 1
+	KYCLevelAdvanced KYCLevel = 2
+)
+
+// String returns the level name
+func (l KYCLevel) String() string {
+	switch l {
+	case KYCLevelNone:
+		return "none"
+	case KYCLevelBasic:
+		return "basic"
+	case KYCLevelAdvanced:
+		return "advanced"
+	default:
+		return fmt.Sprintf("level_%d", int(l))
+	}
+}
+
+// ParseKYCLevel parses a level name ("none", "basic", "advanced") or number
+func ParseKYCLevel(s string) (KYCLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "none":
+		return KYCLevelNone, nil
+	case "basic":
+		return KYCLevelBasic, nil
+	case "advanced":
+		return KYCLevelAdvanced, nil
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("cryptomepay: invalid KYC level %q", s)
+	}
+	return KYCLevel(n), nil
+}
+
+// KYCPolicy maps capabilities to the KYC level they require
+type KYCPolicy struct {
+	// MaxOrderAmount is the largest fiat order amount per level.
+	// Levels missing from the map, or with a value of 0, are unlimited.
+	MaxOrderAmount map[KYCLevel]float64
+
+	// RefundLevel is the minimum level allowed to issue refunds
+	RefundLevel KYCLevel
+
+	// ChainLevel is the minimum level allowed to create orders on each
+	// chain. Chains missing from the map need no verification.
+	ChainLevel map[ChainType]KYCLevel
+}
+
+// DefaultKYCPolicy is the documented client-side policy: unverified
+// merchants may create orders up to 1,000, basic up to 50,000, and advanced
+// are unlimited; refunds require basic verification; no chain requires a
+// level. The server remains authoritative and an explicit
+// MerchantData.MaxOrderAmount takes precedence.
+var DefaultKYCPolicy = KYCPolicy{
+	MaxOrderAmount: map[KYCLevel]float64{
+		KYCLevelNone:  1000,
+		KYCLevelBasic: 50000,
+	},
+	RefundLevel: KYCLevelBasic,
+}
+
+// requiredLevel returns the lowest level whose order limit allows amount
+func (p *KYCPolicy) requiredLevel(amount float64) KYCLevel {
+	levels := make([]int, 0, len(p.MaxOrderAmount))
+	for level := range p.MaxOrderAmount {
+		levels = append(levels, int(level))
+	}
+	sort.Ints(levels)
+
+	for _, level := range levels {
+		if limit := p.MaxOrderAmount[KYCLevel(level)]; limit == 0 || amount <= limit {
+			return KYCLevel(level)
+		}
+	}
+	if len(levels) == 0 {
+		return KYCLevelNone
+	}
+	return KYCLevel(levels[len(levels)-1] + 1)
+}
+
+// KYCLimitError reports a capability the merchant's KYC level does not allow
+type KYCLimitError struct {
+	Capability    string
+	CurrentLevel  KYCLevel
+	RequiredLevel KYCLevel
+	Limit         float64
+}
+
+func (e *KYCLimitError) Error() string {
+	if e.Limit > 0 {
+		return fmt.Sprintf("cryptomepay: %s exceeds the limit of %s for KYC level %s; requires KYC level %s",
+			e.Capability, formatAmount(e.Limit), e.CurrentLevel, e.RequiredLevel)
+	}
+	return fmt.Sprintf("cryptomepay: %s requires KYC level %s (current %s)", e.Capability, e.RequiredLevel, e.CurrentLevel)
+}
+
+// Level returns the merchant's KYC level as a KYCLevel
+func (m *MerchantData) Level() KYCLevel {
+	return KYCLevel(m.KYCLevel)
+}
+
+// CanCreateOrderAmount reports whether an order of amount is allowed under
+// DefaultKYCPolicy or the server-reported MaxOrderAmount
+func (m *MerchantData) CanCreateOrderAmount(amount float64) bool {
+	return m.CheckOrderAmount(amount, nil) == nil
+}
+
+// CheckOrderAmount returns a *KYCLimitError naming the required level when
+// amount exceeds the merchant's limit. A nil policy uses DefaultKYCPolicy.
+func (m *MerchantData) CheckOrderAmount(amount float64, policy *KYCPolicy) error {
+	if policy == nil {
+		policy = &DefaultKYCPolicy
+	}
+
+	limit := m.MaxOrderAmount
+	if limit == 0 {
+		limit = policy.MaxOrderAmount[m.Level()]
+	}
+	if limit == 0 || amount <= limit {
+		return nil
+	}
+
+	required := policy.requiredLevel(amount)
+	if required <= m.Level() {
+		required = m.Level() + 1
+	}
+	return &KYCLimitError{
+		Capability:    fmt.Sprintf("order amount %s", formatAmount(amount)),
+		CurrentLevel:  m.Level(),
+		RequiredLevel: required,
+		Limit:         limit,
+	}
+}
+
+// CanUseChain reports whether orders on chain are allowed under
+// DefaultKYCPolicy
+func (m *MerchantData) CanUseChain(chain ChainType) bool {
+	return m.CheckChain(chain, nil) == nil
+}
+
+// CheckChain returns a *KYCLimitError naming the required level when the
+// policy's ChainLevel puts chain above the merchant's level. A nil policy
+// uses DefaultKYCPolicy.
+func (m *MerchantData) CheckChain(chain ChainType, policy *KYCPolicy) error {
+	if policy == nil {
+		policy = &DefaultKYCPolicy
+	}
+	required, ok := policy.ChainLevel[chain]
+	if !ok || m.Level() >= required {
+		return nil
+	}
+	return &KYCLimitError{Capability: fmt.Sprintf("orders on %s", chain), CurrentLevel: m.Level(), RequiredLevel: required}
+}
+
+// CheckKYC runs CheckOrderAmount and, when chain is set, CheckChain for an
+// order, returning the first *KYCLimitError. A nil policy uses
+// DefaultKYCPolicy.
+func (m *MerchantData) CheckKYC(amount float64, chain ChainType, policy *KYCPolicy) error {
+	if err := m.CheckOrderAmount(amount, policy); err != nil {
+		return err
+	}
+	if chain == "" {
+		return nil
+	}
+	return m.CheckChain(chain, policy)
+}
+
+// CheckRefund returns a *KYCLimitError when the merchant's level may not
+// issue refunds. A nil policy uses DefaultKYCPolicy.
+func (m *MerchantData) CheckRefund(policy *KYCPolicy) error {
+	if policy == nil {
+		policy = &DefaultKYCPolicy
+	}
+	if m.Level() >= policy.RefundLevel {
+		return nil
+	}
+	return &KYCLimitError{Capability: "refunds", CurrentLevel: m.Level(), RequiredLevel: policy.RefundLevel}
+}
+
+// IsKYCFinal reports whether the merchant's KYC review has finished, that
+// is KYCStatus is KYCStatusVerified or KYCStatusRejected in any case
+func (m *MerchantData) IsKYCFinal() bool {
+	status := strings.ToLower(m.KYCStatus)
+	return status == KYCStatusVerified || status == KYCStatusRejected
+}
+
+// KYCStatusChange is sent by WatchKYCStatus when the merchant's KYC status
+// or level changes
+type KYCStatusChange struct {
+	Status   string
+	Level    KYCLevel
+	Merchant *MerchantData
+
+	// Err is set on the last value sent when a poll failed
+	Err error
+}
+
+// WatchKYCStatus polls the merchant profile every pollInterval, bypassing
+// WithMerchantCache, and sends the KYC status and level on the returned
+// channel: first the current one, then each change. Repeated identical
+// polls send nothing.
+//
+// The channel is closed once the status is final (see IsKYCFinal), after
+// that status is sent; when ctx is done; or when a poll fails, after a
+// value carrying the error. Cancel ctx to stop watching early, also when no
+// longer reading from the channel.
+func (c *Client) WatchKYCStatus(ctx context.Context, pollInterval time.Duration, opts ...RequestOption) (<-chan KYCStatusChange, error) {
+	if pollInterval <= 0 {
+		return nil, &ValidationError{Field: "poll_interval", Message: "must be positive"}
+	}
+
+	changes := make(chan KYCStatusChange, 1)
+	go func() {
+		defer close(changes)
+
+		send := func(change KYCStatusChange) bool {
+			select {
+			case changes <- change:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
+		var last *KYCStatusChange
+		for {
+			resp, err := c.fetchMerchantInfo(ctx, opts...)
+			if err == nil && resp.StatusCode != 200 {
+				err = NewAPIError(resp.StatusCode, resp.Message, resp.RequestID)
+			}
+			if err != nil {
+				if ctx.Err() == nil {
+					send(KYCStatusChange{Err: err})
+				}
+				return
+			}
+
+			if merchant := resp.Data; merchant != nil {
+				change := KYCStatusChange{Status: merchant.KYCStatus, Level: merchant.Level(), Merchant: merchant}
+				if last == nil || last.Status != change.Status || last.Level != change.Level {
+					if !send(change) {
+						return
+					}
+					last = &change
+				}
+				if merchant.IsKYCFinal() {
+					return
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return changes, nil
+}
@@ -0,0 +1,165 @@
+package cryptomepay
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseKYCLevel(t *testing.T) {
+	level, err := ParseKYCLevel("Basic")
+	assert.NoError(t, err)
+	assert.Equal(t, KYCLevelBasic, level)
+
+	level, err = ParseKYCLevel("2")
+	assert.NoError(t, err)
+	assert.Equal(t, KYCLevelAdvanced, level)
+	assert.Equal(t, "advanced", level.String())
+
+	_, err = ParseKYCLevel("gold")
+	assert.Error(t, err)
+}
+
+func TestCanCreateOrderAmount(t *testing.T) {
+	merchant := &MerchantData{KYCLevel: int(KYCLevelNone)}
+
+	assert.True(t, merchant.CanCreateOrderAmount(1000))
+	assert.False(t, merchant.CanCreateOrderAmount(1000.01))
+
+	err := merchant.CheckOrderAmount(60000, nil)
+	var limitErr *KYCLimitError
+	assert.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, KYCLevelAdvanced, limitErr.RequiredLevel)
+	assert.Contains(t, err.Error(), "requires KYC level advanced")
+
+	advanced := &MerchantData{KYCLevel: int(KYCLevelAdvanced)}
+	assert.True(t, advanced.CanCreateOrderAmount(1e9))
+
+	// Server-reported limits take precedence over the policy
+	limited := &MerchantData{KYCLevel: int(KYCLevelAdvanced), MaxOrderAmount: 500}
+	assert.False(t, limited.CanCreateOrderAmount(600))
+
+	// Custom policy
+	policy := &KYCPolicy{MaxOrderAmount: map[KYCLevel]float64{KYCLevelNone: 10}}
+	assert.Error(t, merchant.CheckOrderAmount(11, policy))
+}
+
+func TestCheckChain(t *testing.T) {
+	basic := &MerchantData{KYCLevel: int(KYCLevelBasic)}
+
+	// The default policy puts no chain behind a level
+	assert.True(t, (&MerchantData{}).CanUseChain(ChainETH))
+
+	policy := &KYCPolicy{ChainLevel: map[ChainType]KYCLevel{ChainETH: KYCLevelAdvanced, ChainTRC20: KYCLevelBasic}}
+	assert.NoError(t, basic.CheckChain(ChainTRC20, policy))
+	assert.NoError(t, basic.CheckChain(ChainBSC, policy))
+
+	err := basic.CheckChain(ChainETH, policy)
+	var limitErr *KYCLimitError
+	if assert.ErrorAs(t, err, &limitErr) {
+		assert.Equal(t, KYCLevelAdvanced, limitErr.RequiredLevel)
+		assert.Equal(t, KYCLevelBasic, limitErr.CurrentLevel)
+	}
+	assert.EqualError(t, err, "cryptomepay: orders on ETH requires KYC level advanced (current basic)")
+}
+
+func TestCheckKYC(t *testing.T) {
+	merchant := &MerchantData{KYCLevel: int(KYCLevelNone)}
+	policy := &KYCPolicy{
+		MaxOrderAmount: map[KYCLevel]float64{KYCLevelNone: 100},
+		ChainLevel:     map[ChainType]KYCLevel{ChainETH: KYCLevelBasic},
+	}
+
+	assert.NoError(t, merchant.CheckKYC(50, ChainTRC20, policy))
+	assert.NoError(t, merchant.CheckKYC(50, "", policy))
+	assert.ErrorContains(t, merchant.CheckKYC(50, ChainETH, policy), "orders on ETH requires KYC level basic")
+	assert.ErrorContains(t, merchant.CheckKYC(150, ChainTRC20, policy), "order amount 150.00 exceeds the limit")
+
+	// Without a policy the defaults apply
+	assert.NoError(t, merchant.CheckKYC(50, ChainETH, nil))
+	assert.Error(t, merchant.CheckKYC(5000, ChainETH, nil))
+}
+
+func TestCheckRefund(t *testing.T) {
+	assert.Error(t, (&MerchantData{KYCLevel: 0}).CheckRefund(nil))
+	assert.NoError(t, (&MerchantData{KYCLevel: 1}).CheckRefund(nil))
+}
+
+func TestWatchKYCStatus(t *testing.T) {
+	polls := []string{
+		`{"kyc_status":"pending","kyc_level":0}`,
+		`{"kyc_status":"pending","kyc_level":0}`,
+		`{"kyc_status":"pending","kyc_level":1}`,
+		`{"kyc_status":"Verified","kyc_level":1}`,
+	}
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&calls, 1)) - 1
+		if n >= len(polls) {
+			n = len(polls) - 1
+		}
+		w.Write([]byte(`{"status_code":200,"data":` + polls[n] + `}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL), WithMerchantCache(time.Hour))
+
+	changes, err := client.WatchKYCStatus(context.Background(), time.Millisecond)
+	assert.NoError(t, err)
+
+	var got []KYCStatusChange
+	for change := range changes {
+		got = append(got, change)
+	}
+	if assert.Len(t, got, 3) {
+		assert.Equal(t, KYCStatusPending, got[0].Status)
+		assert.Equal(t, KYCLevelNone, got[0].Level)
+		assert.Equal(t, KYCLevelBasic, got[1].Level)
+		assert.Equal(t, "Verified", got[2].Status)
+		assert.True(t, got[2].Merchant.IsKYCFinal())
+		assert.NoError(t, got[2].Err)
+	}
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls), "polling stops at a final status")
+}
+
+func TestWatchKYCStatusStops(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Fail") != "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status_code":1001,"message":"invalid api key"}`))
+			return
+		}
+		w.Write([]byte(`{"status_code":200,"data":{"kyc_status":"pending"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	_, err := client.WatchKYCStatus(context.Background(), 0)
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+
+	// Canceling closes the channel, even with nobody reading
+	ctx, cancel := context.WithCancel(context.Background())
+	changes, err := client.WatchKYCStatus(ctx, time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, KYCStatusPending, (<-changes).Status)
+	cancel()
+	for range changes {
+	}
+
+	// A failed poll is sent before the channel closes
+	changes, _ = client.WatchKYCStatus(context.Background(), time.Millisecond, WithRequestHeader("X-Fail", "1"))
+	change := <-changes
+	var apiErr *APIError
+	if assert.ErrorAs(t, change.Err, &apiErr) {
+		assert.True(t, apiErr.IsAuthError())
+	}
+	_, open := <-changes
+	assert.False(t, open)
+}
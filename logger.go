@@ -0,0 +1,81 @@
+package cryptomepay
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogLevel is the severity of a log event
+type LogLevel int
+
+// Log levels, from most to least verbose
+const (
+	LevelDebug LogLevel = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	}
+	return "unknown"
+}
+
+// Logger receives structured log events from the client. fields holds
+// alternating keys and values, as with slog. The api secret is never
+// logged, and api keys and signatures only in redacted form.
+type Logger interface {
+	Log(ctx context.Context, level LogLevel, msg string, fields ...any)
+}
+
+// nopLogger discards every event; it is the default Logger
+type nopLogger struct{}
+
+func (nopLogger) Log(context.Context, LogLevel, string, ...any) {}
+
+// WithLogger sends the client's log events to l: each outgoing request,
+// its response status and duration, retries, signature generation and
+// ListOrders page splitting. A nil l disables logging.
+func WithLogger(l Logger) Option {
+	return func(c *Client) {
+		if l == nil {
+			l = nopLogger{}
+		}
+		c.logger = l
+	}
+}
+
+// slogLogger adapts a *slog.Logger to Logger
+type slogLogger struct {
+	l *slog.Logger
+}
+
+// NewSlogLogger returns a Logger writing to l
+func NewSlogLogger(l *slog.Logger) Logger {
+	return slogLogger{l: l}
+}
+
+func (s slogLogger) Log(ctx context.Context, level LogLevel, msg string, fields ...any) {
+	s.l.Log(ctx, slogLevel(level), msg, fields...)
+}
+
+func slogLevel(level LogLevel) slog.Level {
+	switch level {
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelError:
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
@@ -0,0 +1,116 @@
+package cryptomepay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type logEvent struct {
+	level  LogLevel
+	msg    string
+	fields []any
+}
+
+type recordingLogger struct {
+	mu     sync.Mutex
+	events []logEvent
+}
+
+func (r *recordingLogger) Log(_ context.Context, level LogLevel, msg string, fields ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, logEvent{level, msg, fields})
+}
+
+func (r *recordingLogger) messages() []string {
+	var msgs []string
+	for _, e := range r.events {
+		msgs = append(msgs, e.msg)
+	}
+	return msgs
+}
+
+func TestLoggerEvents(t *testing.T) {
+	var calls int32
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		signature, _ = body["signature"].(string)
+
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(PaymentResponse{StatusCode: 200, Data: &PaymentData{TradeID: "CP1"}})
+	}))
+	defer server.Close()
+
+	logger := &recordingLogger{}
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithRetry(2, time.Millisecond),
+		WithLogger(logger),
+	)
+
+	_, err := client.CreatePayment(&CreatePaymentParams{OrderID: "ORDER_001", Amount: 1, NotifyURL: "https://example.com/webhook"},
+		WithIdempotencyKey("key-1"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"cryptomepay: signed request",
+		"cryptomepay: sending request",
+		"cryptomepay: response received",
+		"cryptomepay: retrying request",
+		"cryptomepay: sending request",
+		"cryptomepay: response received",
+	}, logger.messages())
+	assert.Equal(t, LevelWarn, logger.events[3].level)
+
+	// Secrets never reach the logger
+	all := fmt.Sprint(logger.events)
+	assert.NotEmpty(t, signature)
+	assert.NotContains(t, all, "test_secret")
+	assert.NotContains(t, all, signature)
+}
+
+func TestLoggerPageSplitWarning(t *testing.T) {
+	calls := 0
+	server := newOrdersServer(30, &calls)
+	defer server.Close()
+
+	logger := &recordingLogger{}
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithMaxPageSize(10),
+		WithLogger(logger),
+	)
+
+	_, err := client.ListOrders(&ListOrdersParams{PageSize: 25})
+	assert.NoError(t, err)
+	assert.Equal(t, LevelWarn, logger.events[0].level)
+	assert.Contains(t, logger.events[0].msg, "splitting")
+}
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	logger.Log(context.Background(), LevelWarn, "cryptomepay: retrying request", "attempt", 2)
+
+	out := buf.String()
+	assert.True(t, strings.Contains(out, "level=WARN"), out)
+	assert.Contains(t, out, "attempt=2")
+}
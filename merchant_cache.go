@@ -0,0 +1,78 @@
+package cryptomepay
+
+import (
+	"context"
+	"time"
+)
+
+// WithMerchantCache caches successful GetMerchantInfo responses per api key
+// for ttl. Within the window GetMerchantInfo answers from memory, with
+// Attempts set to 0; after it the next call fetches again. Use
+// GetMerchantInfoFresh to bypass the cache. A ttl of 0 disables caching.
+//
+// Cached responses are shared by every call regardless of its request
+// options, and by clients created with Clone.
+func WithMerchantCache(ttl time.Duration) Option {
+	return func(c *Client) {
+		if ttl <= 0 {
+			c.merchantCache = nil
+			return
+		}
+		c.merchantCache = newTTLCache(ttl, (*MerchantResponse).copy)
+	}
+}
+
+// copy returns r with its own Data, so callers cannot change a cached value
+func (r *MerchantResponse) copy() *MerchantResponse {
+	out := *r
+	if r.Data != nil {
+		data := *r.Data
+		out.Data = &data
+	}
+	return &out
+}
+
+// GetMerchantInfo gets the merchant profile, from the cache when
+// WithMerchantCache is set and the cached value has not expired
+func (c *Client) GetMerchantInfo(opts ...RequestOption) (*MerchantResponse, error) {
+	ctx := context.Background()
+	if c.merchantCache == nil {
+		return c.fetchMerchantInfo(ctx, opts...)
+	}
+	apiKey := c.credentials().apiKey
+	resp, hit, err := c.merchantCache.getOrFetch(apiKey, func() (*MerchantResponse, error) {
+		return c.getMerchantInfoFresh(ctx, apiKey, opts...)
+	})
+	if hit {
+		return cached(resp), nil
+	}
+	return resp, err
+}
+
+// GetMerchantInfoFresh gets the merchant profile from the API, bypassing
+// and then updating the cache set with WithMerchantCache
+func (c *Client) GetMerchantInfoFresh(opts ...RequestOption) (*MerchantResponse, error) {
+	return c.getMerchantInfoFresh(context.Background(), c.credentials().apiKey, opts...)
+}
+
+// getMerchantInfoFresh fetches the profile for apiKey and caches it
+func (c *Client) getMerchantInfoFresh(ctx context.Context, apiKey string, opts ...RequestOption) (*MerchantResponse, error) {
+	resp, err := c.fetchMerchantInfo(ctx, append([]RequestOption{withAPIKey(apiKey)}, opts...)...)
+	if err == nil && resp.Data != nil && c.merchantCache != nil {
+		c.merchantCache.put(apiKey, resp)
+	}
+	return resp, err
+}
+
+func (c *Client) fetchMerchantInfo(ctx context.Context, opts ...RequestOption) (*MerchantResponse, error) {
+	var resp MerchantResponse
+	err := c.request(ctx, "GET", "/merchant/info", nil, &resp, opts...)
+	return &resp, err
+}
+
+// cached marks resp as answered without an HTTP attempt
+func cached(resp *MerchantResponse) *MerchantResponse {
+	resp.Attempts = 0
+	resp.RateLimitWait = 0
+	return resp
+}
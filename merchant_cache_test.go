@@ -0,0 +1,128 @@
+package cryptomepay
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// merchantServer answers /merchant/info with the calling api key as the name
+func merchantServer(calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		key := r.Header.Get("Authorization")[len("Bearer "):]
+		w.Write([]byte(`{"status_code":200,"data":{"name":"` + key + `","kyc_status":"verified"}}`))
+	}))
+}
+
+func TestMerchantCache(t *testing.T) {
+	var calls int32
+	server := merchantServer(&calls)
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithMerchantCache(50*time.Millisecond),
+	)
+
+	resp, err := client.GetMerchantInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, resp.Attempts)
+
+	// Changing a cached response must not affect the next caller
+	resp.Data.Name = "changed"
+
+	resp, err = client.GetMerchantInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "sk_test_key", resp.Data.Name)
+	assert.Equal(t, 0, resp.Attempts)
+	assert.Equal(t, int32(1), calls)
+
+	resp, err = client.GetMerchantInfoFresh()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, resp.Attempts)
+	assert.Equal(t, int32(2), calls)
+
+	time.Sleep(60 * time.Millisecond)
+	_, err = client.GetMerchantInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), calls)
+
+	// Clones share the cache, keyed by api key
+	other := client.Clone(WithCredentials("sk_other_key", "other_secret"))
+	resp, err = other.GetMerchantInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "sk_other_key", resp.Data.Name)
+	_, err = client.Clone().GetMerchantInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(4), calls)
+}
+
+func TestMerchantCacheSkipsErrors(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"status_code":200,"data":{"name":"Shop"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithMerchantCache(time.Minute),
+	)
+
+	_, err := client.GetMerchantInfo()
+	assert.Error(t, err)
+	resp, err := client.GetMerchantInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "Shop", resp.Data.Name)
+	assert.Equal(t, int32(2), calls)
+}
+
+func TestMerchantCacheConcurrentMisses(t *testing.T) {
+	var calls int32
+	server := merchantServer(&calls)
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithMerchantCache(time.Minute),
+	)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.GetMerchantInfo()
+			assert.NoError(t, err)
+			assert.Equal(t, "sk_test_key", resp.Data.Name)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), calls)
+}
+
+func TestMerchantCacheDisabled(t *testing.T) {
+	var calls int32
+	server := merchantServer(&calls)
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithMerchantCache(time.Minute),
+		WithMerchantCache(0),
+	)
+
+	client.GetMerchantInfo()
+	client.GetMerchantInfo()
+	assert.Equal(t, int32(2), calls)
+}
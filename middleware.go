@@ -0,0 +1,47 @@
+package cryptomepay
+
+import "net/http"
+
+// RoundTripFunc sends a single HTTP request and returns its response
+type RoundTripFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper
+func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps the round trip of every API call, see WithMiddleware
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// WithMiddleware adds mw around every HTTP attempt, including retries. It
+// sees the final signed request with all headers set, may change headers,
+// and may return a response without calling next, for example a synthetic
+// one in tests. Middleware added first runs outermost.
+func WithMiddleware(mw Middleware) Option {
+	return func(c *Client) {
+		// Cap the slice so a Clone adding middleware never shares the array
+		c.middleware = append(c.middleware[:len(c.middleware):len(c.middleware)], mw)
+	}
+}
+
+// roundTrip sends req through the middleware chain to the HTTP client
+func (c *Client) roundTrip(ro *requestOptions, req *http.Request) (*http.Response, error) {
+	httpClient := c.httpClient
+	if ro.timeout > 0 {
+		// The call's context carries the timeout instead
+		withoutTimeout := *c.httpClient
+		withoutTimeout.Timeout = 0
+		httpClient = &withoutTimeout
+	}
+
+	next := RoundTripFunc(httpClient.Do)
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		next = c.middleware[i](next)
+	}
+	resp, err := next(req)
+	if resp != nil && resp.Body == nil {
+		// Synthetic responses may leave the body unset
+		resp.Body = http.NoBody
+	}
+	return resp, err
+}
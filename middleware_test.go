@@ -0,0 +1,109 @@
+package cryptomepay
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddlewareOrderAndHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "trace-1", r.Header.Get("Traceparent"))
+		json.NewEncoder(w).Encode(MerchantResponse{StatusCode: 200, Data: &MerchantData{Name: "Shop"}})
+	}))
+	defer server.Close()
+
+	var calls []string
+	trace := func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			calls = append(calls, "trace")
+			req.Header.Set("Traceparent", "trace-1")
+			return next(req)
+		}
+	}
+	metrics := func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			calls = append(calls, "metrics")
+			assert.Equal(t, "Bearer sk_test_key", req.Header.Get("Authorization"))
+			resp, err := next(req)
+			calls = append(calls, "metrics done")
+			return resp, err
+		}
+	}
+
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithMiddleware(trace),
+		WithMiddleware(metrics),
+	)
+
+	resp, err := client.GetMerchantInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "Shop", resp.Data.Name)
+	assert.Equal(t, []string{"trace", "metrics", "metrics done"}, calls)
+}
+
+func TestMiddlewareSeesSignedRequest(t *testing.T) {
+	var body map[string]interface{}
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithMiddleware(func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				json.NewDecoder(req.Body).Decode(&body)
+
+				// Short-circuit with a synthetic response; no server is involved
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     http.Header{"Content-Type": {"application/json"}},
+					Body:       io.NopCloser(strings.NewReader(`{"status_code":200,"data":{"trade_id":"CP_FAKE"}}`)),
+				}, nil
+			}
+		}),
+	)
+
+	resp, err := client.CreatePayment(&CreatePaymentParams{OrderID: "ORDER_001", Amount: 1, NotifyURL: "https://example.com/webhook"})
+	assert.NoError(t, err)
+	assert.Equal(t, "CP_FAKE", resp.Data.TradeID)
+	assert.Equal(t, "ORDER_001", body["order_id"])
+	assert.NotEmpty(t, body["signature"])
+}
+
+func TestMiddlewareCloneDoesNotShareChain(t *testing.T) {
+	noop := func(next RoundTripFunc) RoundTripFunc { return next }
+	base := NewClientWithOptions("sk_test_key", "test_secret", WithMiddleware(noop), WithMiddleware(noop))
+	a := base.Clone(WithMiddleware(noop))
+	b := base.Clone(WithMiddleware(noop), WithMiddleware(noop))
+
+	assert.Len(t, base.middleware, 2)
+	assert.Len(t, a.middleware, 3)
+	assert.Len(t, b.middleware, 4)
+}
+
+func TestMiddlewareSyntheticResponseWithoutBody(t *testing.T) {
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithMiddleware(func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusServiceUnavailable}, nil
+			}
+		}),
+	)
+
+	_, err := client.GetMerchantInfo()
+	var apiErr *APIError
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, http.StatusServiceUnavailable, apiErr.HTTPStatus)
+	}
+}
+
+// syntheticResponse answers every request with status and body
+func syntheticResponse(status int, body string) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body))}, nil
+		}
+	}
+}
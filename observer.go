@@ -0,0 +1,75 @@
+package cryptomepay
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// RequestObservation describes one completed HTTP attempt
+type RequestObservation struct {
+	Method string
+
+	// Endpoint is the API path without its query, e.g. "/merchant/order/query",
+	// so it is safe to use as a metric label
+	Endpoint string
+
+	// Attempt is 1 for the first try and counts up across retries
+	Attempt int
+
+	// Duration covers the HTTP round trip, excluding rate limit waits
+	Duration time.Duration
+
+	// HTTPStatus is the HTTP status code, 0 when no response arrived
+	HTTPStatus int
+
+	// Code is the API status_code of a failed call, 0 otherwise
+	Code int
+
+	// Err is the error of the attempt, nil on success
+	Err error
+}
+
+// Observer receives an observation after every HTTP attempt, for example
+// to feed request counters and latency histograms
+type Observer interface {
+	ObserveRequest(ctx context.Context, o RequestObservation)
+}
+
+// nopObserver discards observations; it is the default Observer
+type nopObserver struct{}
+
+func (nopObserver) ObserveRequest(context.Context, RequestObservation) {}
+
+// WithObserver reports every HTTP attempt, retries included, to o. A nil o
+// disables observations.
+func WithObserver(o Observer) Option {
+	return func(c *Client) {
+		if o == nil {
+			o = nopObserver{}
+		}
+		c.observer = o
+	}
+}
+
+// observe reports a finished attempt to the client's observer
+func (c *Client) observe(ctx context.Context, method, endpoint string, attempt int, start time.Time, httpStatus int, err error) {
+	o := RequestObservation{
+		Method:     method,
+		Endpoint:   endpoint,
+		Attempt:    attempt,
+		Duration:   time.Since(start),
+		HTTPStatus: httpStatus,
+		Err:        err,
+	}
+	if i := strings.IndexByte(o.Endpoint, '?'); i >= 0 {
+		o.Endpoint = o.Endpoint[:i]
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		o.Code = apiErr.StatusCode
+	}
+	c.observer.ObserveRequest(ctx, o)
+}
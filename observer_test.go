@@ -0,0 +1,77 @@
+package cryptomepay
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingObserver collects observations for assertions
+type recordingObserver struct {
+	mu  sync.Mutex
+	obs []RequestObservation
+}
+
+func (r *recordingObserver) ObserveRequest(_ context.Context, o RequestObservation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.obs = append(r.obs, o)
+}
+
+func TestObserverEachAttempt(t *testing.T) {
+	var calls int32
+	server := flakyServer(1, http.StatusServiceUnavailable, &calls)
+	defer server.Close()
+
+	observer := &recordingObserver{}
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithRetry(2, time.Millisecond),
+		WithObserver(observer),
+	)
+
+	_, err := client.QueryPaymentByTradeID("CP1")
+	assert.NoError(t, err)
+	if assert.Len(t, observer.obs, 2) {
+		first, second := observer.obs[0], observer.obs[1]
+		assert.Equal(t, "GET", first.Method)
+		assert.Equal(t, "/merchant/order/query", first.Endpoint, "the query is dropped")
+		assert.Equal(t, 1, first.Attempt)
+		assert.Equal(t, http.StatusServiceUnavailable, first.HTTPStatus)
+		assert.Equal(t, http.StatusServiceUnavailable, first.Code)
+		assert.Error(t, first.Err)
+
+		assert.Equal(t, 2, second.Attempt)
+		assert.Equal(t, http.StatusOK, second.HTTPStatus)
+		assert.Equal(t, 0, second.Code)
+		assert.NoError(t, second.Err)
+		assert.Greater(t, second.Duration, time.Duration(0))
+	}
+}
+
+func TestObserverAPIErrorCode(t *testing.T) {
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithMiddleware(syntheticResponse(http.StatusOK, `{"status_code":10008,"message":"order not found"}`)),
+	)
+	observer := &recordingObserver{}
+	client = client.Clone(WithObserver(observer))
+
+	_, err := client.CancelOrder("CP1")
+	assert.Error(t, err)
+	if assert.Len(t, observer.obs, 1) {
+		assert.Equal(t, "POST", observer.obs[0].Method)
+		assert.Equal(t, "/order/cancel-transaction", observer.obs[0].Endpoint)
+		assert.Equal(t, http.StatusOK, observer.obs[0].HTTPStatus)
+		assert.Equal(t, ErrCodeOrderNotFound, observer.obs[0].Code)
+	}
+
+	// A nil observer switches observations off
+	client = client.Clone(WithObserver(nil))
+	_, err = client.CancelOrder("CP1")
+	assert.Error(t, err)
+	assert.Len(t, observer.obs, 1)
+}
@@ -0,0 +1,96 @@
+package cryptomepay
+
+import "context"
+
+// OrdersIterator walks every order matching a ListOrdersParams, fetching
+// pages as it goes. Use it as:
+//
+//	it := client.ListOrdersAll(ctx, params)
+//	for it.Next() {
+//		order := it.Order()
+//	}
+//	if err := it.Err(); err != nil {
+//		// handle err
+//	}
+type OrdersIterator struct {
+	client *Client
+	ctx    context.Context
+	params ListOrdersParams
+	opts   []RequestOption
+
+	page    []OrderData
+	index   int
+	fetched int
+	done    bool
+	err     error
+}
+
+// ListOrdersAll returns an iterator over all orders matching params,
+// starting at params.Page (default 1). Each page is fetched once.
+//
+// Iteration ends at the first short or empty page, or once Total orders
+// have been read using the Total of the latest page, so a Total that
+// changes between fetches cannot cause an endless loop.
+func (c *Client) ListOrdersAll(ctx context.Context, params *ListOrdersParams, opts ...RequestOption) *OrdersIterator {
+	it := &OrdersIterator{client: c, ctx: ctx, params: *params, opts: opts}
+	if it.params.Page < 1 {
+		it.params.Page = 1
+	}
+	return it
+}
+
+// Next advances to the next order, fetching a page when needed. It returns
+// false when the orders are exhausted or an error occurred.
+func (it *OrdersIterator) Next() bool {
+	if it.index+1 < len(it.page) {
+		it.index++
+		return true
+	}
+	if it.done || it.err != nil {
+		return false
+	}
+
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		return false
+	}
+
+	resp, err := it.client.listOrdersPage(it.ctx, &it.params, it.opts...)
+	if err == nil && resp.StatusCode != 200 {
+		err = NewAPIError(resp.StatusCode, resp.Message, resp.RequestID)
+	}
+	if err != nil {
+		it.err = err
+		return false
+	}
+	if resp.Data == nil || len(resp.Data.List) == 0 {
+		it.done = true
+		return false
+	}
+
+	list := resp.Data.List
+	it.fetched += len(list)
+
+	pageSize := it.params.PageSize
+	if pageSize == 0 {
+		pageSize = resp.Data.PageSize
+	}
+	if len(list) < pageSize || it.fetched >= resp.Data.Total {
+		it.done = true
+	}
+
+	it.page = list
+	it.index = 0
+	it.params.Page++
+	return true
+}
+
+// Order returns the current order. It is only valid after Next returned true.
+func (it *OrdersIterator) Order() OrderData {
+	return it.page[it.index]
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *OrdersIterator) Err() error {
+	return it.err
+}
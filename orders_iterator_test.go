@@ -0,0 +1,98 @@
+package cryptomepay
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListOrdersAll(t *testing.T) {
+	calls := 0
+	server := newOrdersServer(25, &calls)
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	it := client.ListOrdersAll(context.Background(), &ListOrdersParams{PageSize: 10, Status: StatusPaid})
+
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Order().TradeID)
+	}
+	assert.NoError(t, it.Err())
+	assert.Len(t, ids, 25)
+	assert.Equal(t, "CP0", ids[0])
+	assert.Equal(t, "CP24", ids[24])
+	assert.Equal(t, 3, calls)
+}
+
+func TestListOrdersAllExactMultiple(t *testing.T) {
+	calls := 0
+	server := newOrdersServer(20, &calls)
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	it := client.ListOrdersAll(context.Background(), &ListOrdersParams{PageSize: 10})
+	n := 0
+	for it.Next() {
+		n++
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, 20, n)
+	// Total is reached after the second page, so no empty third page is fetched
+	assert.Equal(t, 2, calls)
+}
+
+func TestListOrdersAllGrowingTotal(t *testing.T) {
+	// A server that reports an ever larger total but runs out of orders
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+		var list []OrderData
+		if page <= 2 {
+			list = []OrderData{{TradeID: "A" + strconv.Itoa(page)}, {TradeID: "B" + strconv.Itoa(page)}}
+		}
+		json.NewEncoder(w).Encode(OrderListResponse{
+			StatusCode: 200,
+			Data:       &OrderListData{List: list, Total: 100 * page, PageSize: 2},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	it := client.ListOrdersAll(context.Background(), &ListOrdersParams{PageSize: 2})
+	n := 0
+	for it.Next() {
+		n++
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, 4, n)
+	assert.Equal(t, 3, calls)
+}
+
+func TestListOrdersAllCanceled(t *testing.T) {
+	calls := 0
+	server := newOrdersServer(25, &calls)
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	it := client.ListOrdersAll(ctx, &ListOrdersParams{PageSize: 10})
+	for it.Next() {
+		cancel()
+	}
+	assert.ErrorIs(t, it.Err(), context.Canceled)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,114 @@
+package cryptomepay
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strconv"
+)
+
+// ActualAmountDecimals is the number of decimals the gateway uses for
+// crypto amounts
+const ActualAmountDecimals = 4
+
+// ComputeActualAmount previews the crypto amount for fiatAmount at rate
+// (crypto per fiat unit) using the gateway's rounding rule: the exact product
+// is rounded to decimals places, with halves rounded away from zero. Pass
+// ActualAmountDecimals for the canonical 4-decimal string CreatePayment
+// reports and webhooks sign; 0 rounds to a whole number. A NaN or infinite
+// amount or rate, or negative decimals, fail with a *ValidationError.
+func ComputeActualAmount(fiatAmount float64, rate float64, decimals int) (string, error) {
+	if decimals < 0 {
+		return "", &ValidationError{Field: "decimals", Message: "must not be negative"}
+	}
+
+	// Use the shortest decimal representation of each float so that e.g.
+	// 19.99 is treated as exactly 19.99 rather than its binary approximation
+	amount, ok := new(big.Rat).SetString(strconv.FormatFloat(fiatAmount, 'f', -1, 64))
+	if !ok {
+		return "", &ValidationError{Field: "amount", Message: fmt.Sprintf("must be finite, got %v", fiatAmount)}
+	}
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(rate, 'f', -1, 64))
+	if !ok {
+		return "", &ValidationError{Field: "exchange_rate", Message: fmt.Sprintf("must be finite, got %v", rate)}
+	}
+
+	return new(big.Rat).Mul(amount, r).FloatString(decimals), nil
+}
+
+// ExpectedActualAmount recomputes the crypto amount from Amount and
+// ExchangeRate with ComputeActualAmount. ok is false when the gateway
+// reported no rate, or a value that is not finite.
+func (d *PaymentData) ExpectedActualAmount() (amount float64, ok bool) {
+	return expectedActualAmount(d.Amount, d.ExchangeRate)
+}
+
+// ExpectedActualAmount recomputes the crypto amount from Amount and
+// ExchangeRate with ComputeActualAmount. ok is false when the gateway
+// reported no rate, or a value that is not finite.
+func (d *OrderData) ExpectedActualAmount() (amount float64, ok bool) {
+	return expectedActualAmount(d.Amount, d.ExchangeRate)
+}
+
+func expectedActualAmount(amount, rate float64) (float64, bool) {
+	if rate == 0 {
+		return 0, false
+	}
+	s, err := ComputeActualAmount(amount, rate, ActualAmountDecimals)
+	if err != nil {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	return v, err == nil
+}
+
+// ExchangeRateData is a rate quote for converting a fiat amount on a chain
+type ExchangeRateData struct {
+	ChainType    ChainType `json:"chain_type"`
+	Amount       float64   `json:"amount"`
+	ActualAmount float64   `json:"actual_amount"`
+
+	// ExchangeRate is the crypto amount per fiat unit and RateTimestamp the
+	// unix time it was quoted
+	ExchangeRate  float64 `json:"exchange_rate"`
+	RateTimestamp int64   `json:"rate_timestamp"`
+}
+
+// ExchangeRateResponse is the API response for GetExchangeRate
+type ExchangeRateResponse struct {
+	StatusCode int               `json:"status_code"`
+	Message    string            `json:"message"`
+	Data       *ExchangeRateData `json:"data"`
+	RequestID  string            `json:"request_id"`
+
+	ResponseMeta
+}
+
+// GetExchangeRate quotes the current rate for amount on chainType without
+// creating an order, for example to show the expected crypto amount at
+// checkout. The quote is not reserved; CreatePayment may use a newer rate.
+// When the rate service is down the call fails with an *APIError with code
+// ErrCodeExchangeRateError.
+//
+// If the gateway reports a rate without an actual amount, ActualAmount is
+// filled in with ComputeActualAmount.
+func (c *Client) GetExchangeRate(chainType ChainType, amount float64, opts ...RequestOption) (*ExchangeRateResponse, error) {
+	if c.checkChainType && !chainType.Valid() {
+		return nil, &ValidationError{Field: "chain_type", Message: fmt.Sprintf("unknown chain %q", chainType)}
+	}
+	if !(amount > 0) {
+		return nil, &ValidationError{Field: "amount", Message: "must be greater than 0"}
+	}
+
+	query := url.Values{}
+	query.Set("chain_type", string(chainType))
+	query.Set("amount", formatAmount(amount))
+
+	var resp ExchangeRateResponse
+	err := c.request(context.Background(), "GET", "/merchant/exchange-rate?"+query.Encode(), nil, &resp, opts...)
+	if err == nil && resp.Data != nil && resp.Data.ActualAmount == 0 && resp.Data.ExchangeRate > 0 {
+		resp.Data.ActualAmount, _ = expectedActualAmount(resp.Data.Amount, resp.Data.ExchangeRate)
+	}
+	return &resp, err
+}
@@ -0,0 +1,115 @@
+package cryptomepay
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpectedActualAmount(t *testing.T) {
+	var order OrderData
+	err := json.Unmarshal([]byte(`{"amount":100,"actual_amount":15.625,"exchange_rate":0.15625,"rate_timestamp":1700000000}`), &order)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1700000000), order.RateTimestamp)
+
+	amount, ok := order.ExpectedActualAmount()
+	assert.True(t, ok)
+	assert.Equal(t, order.ActualAmount, amount)
+
+	// Responses without a rate stay decodable
+	payment := PaymentData{Amount: 100, ActualAmount: 15.625}
+	_, ok = payment.ExpectedActualAmount()
+	assert.False(t, ok)
+}
+
+func TestComputeActualAmount(t *testing.T) {
+	tests := []struct {
+		amount, rate float64
+		decimals     int
+		want         string
+	}{
+		{100, 0.15625, ActualAmountDecimals, "15.6250"},
+		// 19.99 * 0.13835 = 2.7656165 is computed exactly before rounding
+		{19.99, 0.13835, 4, "2.7656"},
+		// Halves round away from zero: 1.00005 -> 1.0001
+		{1, 1.00005, 4, "1.0001"},
+		{1, 1.004, 2, "1.00"},
+		{1, 1.00005, 6, "1.000050"},
+		// 0 decimals is a whole number, not the default precision
+		{100, 0.15625, 0, "16"},
+		{100, 0.145, 0, "15"},
+	}
+	for _, tt := range tests {
+		got, err := ComputeActualAmount(tt.amount, tt.rate, tt.decimals)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, got, "%v * %v to %d decimals", tt.amount, tt.rate, tt.decimals)
+	}
+}
+
+func TestComputeActualAmountInvalid(t *testing.T) {
+	tests := []struct {
+		amount, rate float64
+		decimals     int
+		field        string
+	}{
+		{math.NaN(), 0.15, 4, "amount"},
+		{math.Inf(1), 0.15, 4, "amount"},
+		{100, math.Inf(-1), 4, "exchange_rate"},
+		{100, math.NaN(), 4, "exchange_rate"},
+		{100, 0.15, -1, "decimals"},
+	}
+	for _, tt := range tests {
+		_, err := ComputeActualAmount(tt.amount, tt.rate, tt.decimals)
+		var validationErr *ValidationError
+		if assert.ErrorAs(t, err, &validationErr) {
+			assert.Equal(t, tt.field, validationErr.Field)
+		}
+	}
+
+	_, ok := (&OrderData{Amount: 100, ExchangeRate: math.Inf(1)}).ExpectedActualAmount()
+	assert.False(t, ok)
+}
+
+func TestGetExchangeRate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/merchant/exchange-rate", r.URL.Path)
+		assert.Equal(t, "BSC", r.URL.Query().Get("chain_type"))
+		assert.Equal(t, "100.00", r.URL.Query().Get("amount"))
+
+		// No actual_amount: the client computes it from the rate
+		w.Write([]byte(`{"status_code":200,"data":{"chain_type":"BSC","amount":100,"exchange_rate":0.15625,"rate_timestamp":1700000000}}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+	resp, err := client.GetExchangeRate(ChainBSC, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.15625, resp.Data.ExchangeRate)
+	assert.Equal(t, 15.625, resp.Data.ActualAmount)
+	assert.Equal(t, int64(1700000000), resp.Data.RateTimestamp)
+}
+
+func TestGetExchangeRateErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ExchangeRateResponse{StatusCode: ErrCodeExchangeRateError, Message: "rate service unavailable"})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+	_, err := client.GetExchangeRate(ChainTRC20, 10)
+	var apiErr *APIError
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, ErrCodeExchangeRateError, apiErr.StatusCode)
+	}
+
+	var validationErr *ValidationError
+	_, err = client.GetExchangeRate("SOLANA", 10)
+	assert.ErrorAs(t, err, &validationErr)
+	_, err = client.GetExchangeRate(ChainTRC20, 0)
+	assert.ErrorAs(t, err, &validationErr)
+}
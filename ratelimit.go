@@ -0,0 +1,42 @@
+package cryptomepay
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// WithRateLimit throttles outgoing HTTP attempts, retries included, to rps
+// per second with bursts of up to burst. Calls wait for the limiter, or
+// fail with their context's error, instead of hitting
+// ErrCodeRateLimitExceeded or ErrCodeBurstLimitExceeded. The limiter is
+// shared by all goroutines using the client; the time spent waiting is
+// reported in ResponseMeta.RateLimitWait. A clone gets its own limiter with
+// the same settings. rps <= 0 disables the limit.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(c *Client) {
+		c.limiter = newLimiter(rps, burst)
+	}
+}
+
+func newLimiter(rps float64, burst int) *rate.Limiter {
+	if rps <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return rate.NewLimiter(rate.Limit(rps), burst)
+}
+
+// waitRateLimit blocks until the limiter admits one request and returns
+// how long that took
+func (c *Client) waitRateLimit(ctx context.Context) (time.Duration, error) {
+	if c.limiter == nil {
+		return 0, nil
+	}
+	start := time.Now()
+	err := c.limiter.Wait(ctx)
+	return time.Since(start), err
+}
@@ -0,0 +1,73 @@
+package cryptomepay
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(MerchantResponse{StatusCode: 200})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithRateLimit(20, 2),
+	)
+
+	// Two requests use the burst, the next four wait 50ms each
+	start := time.Now()
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var waited time.Duration
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.GetMerchantInfo()
+			assert.NoError(t, err)
+			mu.Lock()
+			waited += resp.RateLimitWait
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+	assert.Greater(t, waited, time.Duration(0))
+}
+
+func TestRateLimitRespectsContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(OrderResponse{StatusCode: 200, Data: &OrderData{}})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithRateLimit(0.1, 1),
+	)
+
+	_, err := client.QueryPayment(context.Background(), QueryParams{TradeID: "CP1"})
+	assert.NoError(t, err)
+
+	// The next token is ten seconds away
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = client.QueryPayment(ctx, QueryParams{TradeID: "CP1"})
+	assert.Error(t, err)
+
+	// A clone has its own limiter
+	clone := client.Clone()
+	_, err = clone.QueryPayment(context.Background(), QueryParams{TradeID: "CP1"})
+	assert.NoError(t, err)
+	assert.Equal(t, 0.1, clone.Diagnostics().RateLimit)
+}
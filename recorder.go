@@ -0,0 +1,248 @@
+package cryptomepay
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// ErrNoRecordedInteraction is returned in playback mode when the cassette
+// has no interaction matching a request
+var ErrNoRecordedInteraction = errors.New("cryptomepay: no recorded interaction for request")
+
+// ignoredBodyFields are dropped from recorded request bodies: timestamp,
+// nonce and signature change on every call, and api_key is a credential
+// that must not end up in a cassette
+var ignoredBodyFields = []string{"api_key", "timestamp", "nonce", "signature"}
+
+// RecorderMode selects how a Recorder uses its cassette
+type RecorderMode int
+
+const (
+	// RecorderAuto replays recorded interactions and records new ones
+	// from the real API
+	RecorderAuto RecorderMode = iota
+	// RecorderPlayback only replays; unmatched requests fail with
+	// ErrNoRecordedInteraction and never reach the network
+	RecorderPlayback
+)
+
+// Interaction is one recorded request/response pair
+type Interaction struct {
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	Body       string      `json:"body,omitempty"`
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Response   string      `json:"response"`
+}
+
+// Recorder is an http.RoundTripper that records interactions to a cassette
+// file and replays them, for deterministic tests without a live gateway.
+//
+// Requests match on method, path with query, and the JSON body without its
+// api_key, timestamp, nonce and signature. Request headers are never written
+// to the cassette, so playback needs no real credentials. Repeated identical
+// requests replay the recorded responses in order, then keep returning the
+// last one.
+//
+// Recorded bodies are limited to DefaultMaxResponseSize, decompressed.
+type Recorder struct {
+	path string
+	mode RecorderMode
+	next http.RoundTripper
+
+	mu           sync.Mutex
+	loaded       bool
+	interactions []Interaction
+	replayed     map[string]int
+}
+
+// NewRecorder creates a Recorder for the cassette at path. next performs
+// real requests in RecorderAuto mode; nil uses http.DefaultTransport.
+func NewRecorder(path string, mode RecorderMode, next http.RoundTripper) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Recorder{path: path, mode: mode, next: next, replayed: make(map[string]int)}
+}
+
+// WithRecorder records interactions with the API to the cassette at path
+// and replays them on later runs
+func WithRecorder(path string) Option {
+	return withRecorder(path, RecorderAuto)
+}
+
+// WithPlayback replays the cassette at path without contacting the API,
+// for CI runs without credentials
+func WithPlayback(path string) Option {
+	return withRecorder(path, RecorderPlayback)
+}
+
+func withRecorder(path string, mode RecorderMode) Option {
+	return func(c *Client) {
+		// Copy the http.Client so one supplied with WithHTTPClient is not modified
+		hc := *c.httpClient
+		hc.Transport = NewRecorder(path, mode, hc.Transport)
+		c.httpClient = &hc
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	// Compressed requests match, and are stored, like plain ones
+	plain, err := decodeBody(req.Header.Clone(), body, DefaultMaxResponseSize)
+	if err != nil {
+		return nil, err
+	}
+	key := Interaction{Method: req.Method, URL: req.URL.RequestURI(), Body: normalizeRecordedBody(plain)}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	if recorded, ok := r.match(key); ok {
+		return recorded.response(req), nil
+	}
+	if r.mode == RecorderPlayback {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoRecordedInteraction, req.Method, key.URL)
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := readLimited(resp.Body, DefaultMaxResponseSize)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	// The cassette holds text, so compressed responses are stored decoded
+	if respBody, err = decodeBody(resp.Header, respBody, DefaultMaxResponseSize); err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	key.StatusCode = resp.StatusCode
+	key.Header = resp.Header.Clone()
+	key.Response = string(respBody)
+	r.interactions = append(r.interactions, key)
+	r.replayed[key.matchKey()]++
+
+	return resp, r.save()
+}
+
+// load reads the cassette on first use; a missing file is an empty cassette
+func (r *Recorder) load() error {
+	if r.loaded {
+		return nil
+	}
+
+	data, err := os.ReadFile(r.path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("failed to read cassette: %w", err)
+	default:
+		if err := json.Unmarshal(data, &r.interactions); err != nil {
+			return fmt.Errorf("failed to parse cassette %s: %w", r.path, err)
+		}
+	}
+
+	r.loaded = true
+	return nil
+}
+
+// save writes every interaction back to the cassette
+func (r *Recorder) save() error {
+	data, err := json.MarshalIndent(r.interactions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cassette: %w", err)
+	}
+	if err := os.WriteFile(r.path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// match returns the next recorded interaction for key
+func (r *Recorder) match(key Interaction) (Interaction, bool) {
+	var matches []Interaction
+	for _, in := range r.interactions {
+		if in.matchKey() == key.matchKey() {
+			matches = append(matches, in)
+		}
+	}
+	if len(matches) == 0 {
+		return Interaction{}, false
+	}
+
+	n := r.replayed[key.matchKey()]
+	r.replayed[key.matchKey()]++
+	if n >= len(matches) {
+		n = len(matches) - 1
+	}
+	return matches[n], true
+}
+
+func (in Interaction) matchKey() string {
+	return in.Method + " " + in.URL + " " + in.Body
+}
+
+// response builds an *http.Response for req from a recorded interaction
+func (in Interaction) response(req *http.Request) *http.Response {
+	header := in.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.StatusCode, http.StatusText(in.StatusCode)),
+		StatusCode:    in.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(in.Response))),
+		ContentLength: int64(len(in.Response)),
+		Request:       req,
+	}
+}
+
+// normalizeRecordedBody drops the ignored fields from a JSON body and
+// re-encodes it with sorted keys. Non-JSON bodies are kept as they are.
+func normalizeRecordedBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return string(body)
+	}
+	for _, k := range ignoredBodyFields {
+		delete(fields, k)
+	}
+
+	normalized, err := json.Marshal(fields)
+	if err != nil {
+		return string(body)
+	}
+	return string(normalized)
+}
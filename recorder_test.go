@@ -0,0 +1,75 @@
+package cryptomepay
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorderRecordAndReplay(t *testing.T) {
+	cassette := filepath.Join(t.TempDir(), "cassette.json")
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		json.NewEncoder(w).Encode(PaymentResponse{StatusCode: 200, Data: &PaymentData{TradeID: "CP1"}})
+	}))
+
+	params := &CreatePaymentParams{OrderID: "ORDER_001", Amount: 100, NotifyURL: "https://example.com/webhook"}
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL), WithRecorder(cassette))
+	resp, err := client.CreatePayment(params)
+	assert.NoError(t, err)
+	assert.Equal(t, "CP1", resp.Data.TradeID)
+
+	// Replaying works with a fresh nonce and timestamp
+	resp, err = client.CreatePayment(params)
+	assert.NoError(t, err)
+	assert.Equal(t, "CP1", resp.Data.TradeID)
+	assert.Equal(t, 1, calls)
+
+	data, err := os.ReadFile(cassette)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "sk_test_key")
+	assert.NotContains(t, string(data), "nonce")
+
+	// Playback never needs the server
+	server.Close()
+	client = NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL), WithPlayback(cassette))
+	resp, err = client.CreatePayment(params)
+	assert.NoError(t, err)
+	assert.Equal(t, "CP1", resp.Data.TradeID)
+
+	_, err = client.QueryPaymentByTradeID("CP1")
+	assert.ErrorIs(t, err, ErrNoRecordedInteraction)
+}
+
+func TestRecorderReplaysInOrder(t *testing.T) {
+	cassette := filepath.Join(t.TempDir(), "cassette.json")
+	os.WriteFile(cassette, []byte(`[
+		{"method":"GET","url":"/merchant/info","status_code":200,"response":"{\"status_code\":200,\"data\":{\"name\":\"first\"}}"},
+		{"method":"GET","url":"/merchant/info","status_code":200,"response":"{\"status_code\":200,\"data\":{\"name\":\"second\"}}"}
+	]`), 0o644)
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL("http://127.0.0.1:1"), WithPlayback(cassette))
+
+	var names []string
+	for i := 0; i < 3; i++ {
+		resp, err := client.GetMerchantInfo()
+		assert.NoError(t, err)
+		names = append(names, resp.Data.Name)
+	}
+	assert.Equal(t, "first,second,second", strings.Join(names, ","))
+}
+
+func TestWithRecorderKeepsSuppliedClient(t *testing.T) {
+	hc := &http.Client{}
+	NewClientWithOptions("sk_test_key", "test_secret", WithHTTPClient(hc), WithPlayback("unused.json"))
+	assert.Nil(t, hc.Transport)
+}
@@ -0,0 +1,85 @@
+package cryptomepay
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// refundServer serves order with the query endpoint and records refund bodies
+func refundServer(t *testing.T, order OrderData, refunds *[]map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/merchant/order/query":
+			json.NewEncoder(w).Encode(OrderResponse{StatusCode: 200, Data: &order})
+		case "/order/refund":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			*refunds = append(*refunds, body)
+			json.NewEncoder(w).Encode(RefundResponse{StatusCode: 200, Data: &RefundData{RefundID: "RF1", TradeID: order.TradeID, Status: "pending"}})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+}
+
+func TestRefundOrder(t *testing.T) {
+	var refunds []map[string]interface{}
+	server := refundServer(t, OrderData{TradeID: "CP1", Status: StatusPaid, ActualAmount: 15.625}, &refunds)
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+	resp, err := client.RefundOrder("CP1", &RefundParams{Amount: 0.625, Address: "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", Reason: "overpaid"})
+	assert.NoError(t, err)
+	assert.Equal(t, "RF1", resp.Data.RefundID)
+	assert.Equal(t, "pending", resp.Data.Status)
+
+	if assert.Len(t, refunds, 1) {
+		body := refunds[0]
+		assert.Equal(t, "CP1", body["trade_id"])
+		assert.Equal(t, 0.625, body["amount"])
+		assert.Equal(t, "overpaid", body["reason"])
+
+		signed := map[string]string{"amount": "0.6250"}
+		for _, k := range []string{"api_key", "timestamp", "nonce", "trade_id", "address", "reason"} {
+			signed[k] = body[k].(string)
+		}
+		assert.Equal(t, client.calculateSignature(signed), body["signature"])
+	}
+
+	// The whole received amount may be refunded
+	_, err = client.RefundOrder("CP1", &RefundParams{Amount: 15.625, Address: "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"})
+	assert.NoError(t, err)
+}
+
+func TestRefundOrderNotPaid(t *testing.T) {
+	var refunds []map[string]interface{}
+	server := refundServer(t, OrderData{TradeID: "CP1", Status: StatusPending}, &refunds)
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+	_, err := client.RefundOrder("CP1", &RefundParams{Amount: 1, Address: "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"})
+	assert.ErrorIs(t, err, ErrOrderNotPaid)
+	assert.Empty(t, refunds)
+}
+
+func TestRefundOrderExceedsReceived(t *testing.T) {
+	var refunds []map[string]interface{}
+	server := refundServer(t, OrderData{TradeID: "CP1", Status: StatusPaid, ActualAmount: 15.625}, &refunds)
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+	_, err := client.RefundOrder("CP1", &RefundParams{Amount: 15.6251, Address: "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"})
+	var validationErr *ValidationError
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, "amount", validationErr.Field)
+	}
+	assert.Empty(t, refunds)
+
+	_, err = client.RefundOrder("CP1", &RefundParams{Amount: 1})
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "address", validationErr.Field)
+}
@@ -0,0 +1,367 @@
+package cryptomepay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// RequestOption configures a single API call without changing the client
+type RequestOption func(*requestOptions)
+
+// requestOptions holds per-call settings applied in request
+type requestOptions struct {
+	baseURL string
+
+	// staticAuth sends the api key even when a bearer token is in use
+	staticAuth bool
+
+	// apiKey is the key the call authenticates with when no bearer token
+	// is used, set once per call
+	apiKey string
+
+	idempotencyKey string
+
+	// timeout bounds the whole call and replaces the HTTP client timeout
+	timeout time.Duration
+
+	headers http.Header
+
+	// clientRequestID is sent with every attempt of the call, and
+	// serverRequestID is the gateway's id from the last response header
+	clientRequestID string
+	serverRequestID string
+}
+
+// ResponseMeta describes how a response was obtained. It is embedded in
+// every response type and is not part of the JSON body.
+type ResponseMeta struct {
+	// Attempts is the number of HTTP attempts made, including retries
+	Attempts int `json:"-"`
+
+	// Raw is the HTTP response of the last attempt, nil if none arrived
+	Raw *RawResponse `json:"-"`
+
+	// ClientRequestID is the correlation id sent in ClientRequestIDHeader
+	ClientRequestID string `json:"-"`
+
+	// RateLimitWait is the total time spent waiting for WithRateLimit
+	RateLimitWait time.Duration `json:"-"`
+}
+
+// RawResponse is an HTTP response as received, before decoding
+type RawResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// metaCarrier is implemented by response types embedding ResponseMeta
+type metaCarrier interface {
+	responseMeta() *ResponseMeta
+}
+
+func (m *ResponseMeta) responseMeta() *ResponseMeta {
+	return m
+}
+
+// maxErrorBodySnippet bounds how much of a body is quoted in errors
+const maxErrorBodySnippet = 512
+
+// bodySnippet returns body for an error message, truncated to
+// maxErrorBodySnippet bytes
+func bodySnippet(body []byte) string {
+	if len(body) > maxErrorBodySnippet {
+		return string(body[:maxErrorBodySnippet]) + "…"
+	}
+	return string(body)
+}
+
+// WithRequestBaseURL sends a single call to baseURL instead of the client's
+// base URL, for example to canary a new gateway region.
+func WithRequestBaseURL(baseURL string) RequestOption {
+	return func(o *requestOptions) {
+		o.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithRequestTimeout bounds a single call, retries and rate limit waits
+// included, by timeout. It replaces the client's timeout (see WithTimeout)
+// for the call, so it may be shorter or longer. A deadline on the call's
+// context still applies.
+func WithRequestTimeout(timeout time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithIdempotencyKey sends key in the Idempotency-Key header so the gateway
+// returns the original result for a repeated call. POST requests are only
+// retried (see WithRetry) when a key is set.
+func WithIdempotencyKey(key string) RequestOption {
+	return func(o *requestOptions) {
+		o.idempotencyKey = key
+	}
+}
+
+// WithRequestHeader sets a header on a single call, overriding the same
+// header from WithDefaultHeaders. Headers the client sets itself, such as
+// Authorization, cannot be overridden.
+func WithRequestHeader(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		if o.headers == nil {
+			o.headers = http.Header{}
+		}
+		o.headers.Set(key, value)
+	}
+}
+
+// withStaticAuth authenticates a call with the static api key
+func withStaticAuth() RequestOption {
+	return func(o *requestOptions) {
+		o.staticAuth = true
+	}
+}
+
+// validateBaseURL checks that u is an absolute http or https URL
+func validateBaseURL(u string) error {
+	return validateHTTPURL("base_url", u)
+}
+
+// validateHTTPURL checks that the URL in field is absolute http or https
+func validateHTTPURL(field, u string) error {
+	parsed, err := url.Parse(u)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return &ValidationError{Field: field, Message: fmt.Sprintf("%q is not an absolute http(s) URL", u)}
+	}
+	return nil
+}
+
+// userAgentHeader returns the User-Agent sent with every request
+func (c *Client) userAgentHeader() string {
+	if c.userAgent == "" {
+		return "cryptomepay-go/" + Version
+	}
+	return "cryptomepay-go/" + Version + " " + c.userAgent
+}
+
+// newRequestOptions applies opts to the client's defaults for one call,
+// generating its client request id
+func (c *Client) newRequestOptions(opts []RequestOption) (requestOptions, error) {
+	clientRequestID, err := generateNonce()
+	if err != nil {
+		return requestOptions{}, err
+	}
+	ro := requestOptions{baseURL: c.baseURL, clientRequestID: clientRequestID}
+	for _, opt := range opts {
+		opt(&ro)
+	}
+	if ro.apiKey == "" {
+		ro.apiKey = c.credentials().apiKey
+	}
+	if ro.baseURL != c.baseURL {
+		if err := validateBaseURL(ro.baseURL); err != nil {
+			return ro, err
+		}
+	}
+	return ro, nil
+}
+
+// request makes an HTTP request
+func (c *Client) request(ctx context.Context, method, endpoint string, body interface{}, result interface{}, opts ...RequestOption) error {
+	if err := c.lifecycle.begin(); err != nil {
+		return err
+	}
+	defer c.lifecycle.end()
+
+	if c.transportErr != nil {
+		return c.transportErr
+	}
+
+	ro, err := c.newRequestOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	if ro.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ro.timeout)
+		defer cancel()
+	}
+
+	var jsonBody []byte
+	if body != nil {
+		jsonBody, err = json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+	}
+
+	var waited time.Duration
+	attempt := 0
+	attempts, err := c.retry(ctx, method, &ro, func(ctx context.Context) error {
+		wait, err := c.waitRateLimit(ctx)
+		waited += wait
+		if err != nil {
+			return err
+		}
+
+		attempt++
+		c.logger.Log(ctx, LevelDebug, "cryptomepay: sending request",
+			"method", method, "endpoint", endpoint, "attempt", attempt)
+		start := time.Now()
+		status, err := c.attempt(ctx, &ro, method, ro.baseURL+endpoint, jsonBody, result)
+		c.observe(ctx, method, endpoint, attempt, start, status, err)
+		return err
+	}, result)
+	if m, ok := result.(metaCarrier); ok {
+		m.responseMeta().Attempts = attempts
+		m.responseMeta().RateLimitWait = waited
+		m.responseMeta().ClientRequestID = ro.clientRequestID
+	}
+	if err != nil {
+		return newRequestError(err, &ro)
+	}
+	return nil
+}
+
+// attempt performs a single HTTP round trip, bounded by the per-attempt
+// timeout when one is configured. It returns the HTTP status code, or 0 if
+// no response arrived.
+func (c *Client) attempt(ctx context.Context, ro *requestOptions, method, rawURL string, jsonBody []byte, result interface{}) (int, error) {
+	ro.serverRequestID = ""
+	if c.perAttemptTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.perAttemptTimeout)
+		defer cancel()
+	}
+
+	credential := ro.apiKey
+	if !ro.staticAuth {
+		var err error
+		if credential, err = c.bearerToken(ctx, ro.apiKey); err != nil {
+			return 0, err
+		}
+	}
+
+	req, err := c.newHTTPRequest(ctx, ro, method, rawURL, jsonBody, credential)
+	if err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	resp, err := c.roundTrip(ro, req)
+	if err != nil {
+		c.logger.Log(ctx, LevelError, "cryptomepay: request failed",
+			"method", method, "url", rawURL, "duration", time.Since(start), "error", err)
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	c.syncClock(resp.Header, start, time.Now())
+
+	c.logger.Log(ctx, LevelDebug, "cryptomepay: response received",
+		"method", method, "url", rawURL, "status", resp.StatusCode, "duration", time.Since(start))
+
+	ro.serverRequestID = resp.Header.Get(serverRequestIDHeader)
+	if echoed := resp.Header.Get(ClientRequestIDHeader); echoed != "" && echoed != ro.clientRequestID {
+		c.logger.Log(ctx, LevelWarn, "cryptomepay: response is for another client request id",
+			"sent", ro.clientRequestID, "received", echoed)
+	}
+
+	respBody, err := readLimited(resp.Body, c.maxResponseSize)
+	if errors.Is(err, ErrResponseTooLarge) {
+		return resp.StatusCode, err
+	}
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("failed to read response: %w", err)
+	}
+	if respBody, err = decodeBody(resp.Header, respBody, c.maxResponseSize); err != nil {
+		return resp.StatusCode, err
+	}
+
+	if m, ok := result.(metaCarrier); ok {
+		m.responseMeta().Raw = &RawResponse{StatusCode: resp.StatusCode, Header: resp.Header, Body: respBody}
+	}
+
+	if resp.StatusCode >= 400 {
+		// Keep the envelope available to callers that inspect the response
+		json.Unmarshal(respBody, result)
+		apiErr := newAPIErrorFromBody(resp.StatusCode, respBody)
+		apiErr.RetryAfter = parseRetryAfter(resp.Header, c.now())
+		return resp.StatusCode, apiErr
+	}
+
+	if err := c.verifyResponseSignature(resp, respBody); err != nil {
+		return resp.StatusCode, err
+	}
+
+	if err := json.Unmarshal(respBody, result); err != nil {
+		return resp.StatusCode, &DecodeError{
+			HTTPStatus:  resp.StatusCode,
+			ContentType: resp.Header.Get("Content-Type"),
+			Body:        bodySnippet(respBody),
+			Err:         err,
+		}
+	}
+
+	if c.errorOnNon200 {
+		if apiErr := newAPIErrorFromEnvelope(resp.StatusCode, respBody); apiErr != nil {
+			apiErr.RetryAfter = parseRetryAfter(resp.Header, c.now())
+			return resp.StatusCode, apiErr
+		}
+	}
+
+	return resp.StatusCode, nil
+}
+
+// newHTTPRequest builds the HTTP request for one attempt, authenticated
+// with credential and carrying the caller's and the SDK's headers
+func (c *Client) newHTTPRequest(ctx context.Context, ro *requestOptions, method, rawURL string, jsonBody []byte, credential string) (*http.Request, error) {
+	var reqBody io.Reader
+	var compressed bool
+	if jsonBody != nil {
+		sendBody, ok, err := c.compressBody(jsonBody)
+		if err != nil {
+			return nil, err
+		}
+		reqBody, compressed = bytes.NewReader(sendBody), ok
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Caller headers go first so the ones below always win; values are
+	// copied so middleware changing them cannot affect other requests
+	for k, v := range c.defaultHeaders {
+		req.Header[k] = append([]string(nil), v...)
+	}
+	for k, v := range ro.headers {
+		req.Header[k] = append([]string(nil), v...)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+credential)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	req.Header.Set("User-Agent", c.userAgentHeader())
+	req.Header.Set(ClientRequestIDHeader, ro.clientRequestID)
+	req.Header.Del("Idempotency-Key")
+	if ro.idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", ro.idempotencyKey)
+	}
+	return req, nil
+}
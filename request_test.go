@@ -0,0 +1,148 @@
+package cryptomepay
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPerAttemptTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+		json.NewEncoder(w).Encode(MerchantResponse{StatusCode: 200})
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithPerAttemptTimeout(50*time.Millisecond),
+	)
+
+	start := time.Now()
+	_, err := client.GetMerchantInfo()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestRawResponse(t *testing.T) {
+	page := "<html>" + strings.Repeat("x", 1000) + "</html>"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set("X-Proxy", "edge-1")
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	resp, err := client.GetMerchantInfo()
+	assert.ErrorContains(t, err, "failed to unmarshal response (HTTP 200")
+	assert.ErrorContains(t, err, "<html>xxx")
+	assert.Less(t, len(err.Error()), 750)
+
+	assert.Equal(t, http.StatusOK, resp.Raw.StatusCode)
+	assert.Equal(t, "edge-1", resp.Raw.Header.Get("X-Proxy"))
+	assert.Equal(t, page, string(resp.Raw.Body))
+}
+
+func TestUserAgentAndDefaultHeaders(t *testing.T) {
+	var got []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Clone())
+		json.NewEncoder(w).Encode(MerchantResponse{StatusCode: 200})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithUserAgent("checkout-service/1.2"),
+		WithDefaultHeaders(http.Header{
+			"x-partner-id":  {"partner-7"},
+			"X-Team":        {"payments"},
+			"Authorization": {"Bearer stolen"},
+			"Content-Type":  {"text/plain"},
+		}),
+	)
+
+	_, err := client.GetMerchantInfo(
+		WithRequestHeader("X-Team", "refunds"),
+		WithRequestHeader("User-Agent", "other"),
+	)
+	assert.NoError(t, err)
+	_, err = client.GetMerchantInfo()
+	assert.NoError(t, err)
+
+	if assert.Len(t, got, 2) {
+		first, second := got[0], got[1]
+		assert.Equal(t, "cryptomepay-go/"+Version+" checkout-service/1.2", first.Get("User-Agent"))
+		assert.Equal(t, "partner-7", first.Get("X-Partner-Id"))
+		assert.Equal(t, "refunds", first.Get("X-Team"))
+		assert.Equal(t, "Bearer sk_test_key", first.Get("Authorization"))
+		assert.Equal(t, "application/json", first.Get("Content-Type"))
+
+		assert.Equal(t, "payments", second.Get("X-Team"), "request headers apply to one call only")
+	}
+}
+
+func TestDefaultHeadersClone(t *testing.T) {
+	base := NewClientWithOptions("sk_test_key", "test_secret", WithDefaultHeaders(http.Header{"X-Team": {"payments"}}))
+	clone := base.Clone(WithDefaultHeaders(http.Header{"X-Region": {"eu"}}))
+
+	assert.Equal(t, "", base.defaultHeaders.Get("X-Region"))
+	assert.Equal(t, "payments", clone.defaultHeaders.Get("X-Team"))
+	assert.Equal(t, "eu", clone.defaultHeaders.Get("X-Region"))
+	assert.Equal(t, "cryptomepay-go/"+Version, base.userAgentHeader())
+}
+
+func TestRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(150 * time.Millisecond):
+		case <-r.Context().Done():
+			return
+		}
+		json.NewEncoder(w).Encode(MerchantResponse{StatusCode: 200})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithTimeout(50*time.Millisecond),
+	)
+
+	// The client timeout applies by default
+	_, err := client.GetMerchantInfo()
+	assert.Error(t, err)
+
+	// A longer per-call timeout wins over it
+	_, err = client.GetMerchantInfo(WithRequestTimeout(2 * time.Second))
+	assert.NoError(t, err)
+
+	// And so does a shorter one
+	start := time.Now()
+	_, err = client.GetMerchantInfo(WithRequestTimeout(20 * time.Millisecond))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 120*time.Millisecond)
+}
+
+func TestWithTimeoutKeepsInjectedClient(t *testing.T) {
+	custom := &http.Client{Timeout: 5 * time.Second}
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithHTTPClient(custom),
+		WithTimeout(time.Second),
+	)
+
+	assert.Equal(t, 5*time.Second, custom.Timeout)
+	assert.Equal(t, time.Second, client.httpClient.Timeout)
+}
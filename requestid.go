@@ -0,0 +1,59 @@
+package cryptomepay
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ClientRequestIDHeader carries the correlation id the client generates for
+// each call. Retries of a call reuse its id.
+const ClientRequestIDHeader = "X-Client-Request-Id"
+
+// serverRequestIDHeader is where the gateway may report its request id
+// when the body cannot carry it
+const serverRequestIDHeader = "X-Request-Id"
+
+// RequestError wraps every error of a call that reached the network, so a
+// failure can be quoted to support even when no response was decoded.
+// errors.Is and errors.As see the underlying error, such as an *APIError.
+type RequestError struct {
+	// ClientRequestID is the id sent in ClientRequestIDHeader
+	ClientRequestID string
+
+	// ServerRequestID is the gateway's request_id, empty if none arrived
+	ServerRequestID string
+
+	Err error
+}
+
+func (e *RequestError) Error() string {
+	var apiErr *APIError
+	if e.ServerRequestID == "" || errors.As(e.Err, &apiErr) {
+		// An APIError already names the server's request id
+		return fmt.Sprintf("%v (client_request_id=%s)", e.Err, e.ClientRequestID)
+	}
+	return fmt.Sprintf("%v (client_request_id=%s, request_id=%s)", e.Err, e.ClientRequestID, e.ServerRequestID)
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// RequestID returns the gateway's request id, or the client's when the
+// gateway sent none
+func (e *RequestError) RequestID() string {
+	if e.ServerRequestID != "" {
+		return e.ServerRequestID
+	}
+	return e.ClientRequestID
+}
+
+// newRequestError wraps err with the ids of the call that produced it
+func newRequestError(err error, ro *requestOptions) *RequestError {
+	serverID := ro.serverRequestID
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RequestID != "" {
+		serverID = apiErr.RequestID
+	}
+	return &RequestError{ClientRequestID: ro.clientRequestID, ServerRequestID: serverID, Err: err}
+}
@@ -0,0 +1,95 @@
+package cryptomepay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientRequestIDAcrossRetries(t *testing.T) {
+	var ids []string
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids = append(ids, r.Header.Get(ClientRequestIDHeader))
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(MerchantResponse{StatusCode: 200})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL), WithRetry(2, time.Millisecond))
+	resp, err := client.GetMerchantInfo()
+	assert.NoError(t, err)
+	if assert.Len(t, ids, 2) {
+		assert.NotEmpty(t, ids[0])
+		assert.Equal(t, ids[0], ids[1])
+		assert.Equal(t, ids[0], resp.ClientRequestID)
+	}
+
+	next, _ := client.GetMerchantInfo()
+	assert.NotEqual(t, resp.ClientRequestID, next.ClientRequestID)
+}
+
+func TestRequestErrorUnmarshal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req_gw_1")
+		w.Write([]byte("<html>bad gateway</html>"))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+	resp, err := client.GetMerchantInfo()
+
+	var reqErr *RequestError
+	if assert.ErrorAs(t, err, &reqErr) {
+		assert.Equal(t, resp.ClientRequestID, reqErr.ClientRequestID)
+		assert.Equal(t, "req_gw_1", reqErr.ServerRequestID)
+		assert.Equal(t, "req_gw_1", reqErr.RequestID())
+		assert.Contains(t, err.Error(), "client_request_id="+resp.ClientRequestID)
+		assert.Contains(t, err.Error(), "request_id=req_gw_1")
+	}
+}
+
+func TestRequestErrorNetwork(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+	_, err := client.GetMerchantInfo()
+
+	var reqErr *RequestError
+	if assert.ErrorAs(t, err, &reqErr) {
+		assert.NotEmpty(t, reqErr.ClientRequestID)
+		assert.Empty(t, reqErr.ServerRequestID)
+		assert.Equal(t, reqErr.ClientRequestID, reqErr.RequestID())
+	}
+}
+
+func TestRequestErrorWrapsAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(OrderResponse{StatusCode: ErrCodeOrderNotFound, Message: "not found", RequestID: "req_api_1"})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := client.QueryPayment(ctx, QueryParams{TradeID: "CP1"})
+
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	var reqErr *RequestError
+	if assert.ErrorAs(t, err, &reqErr) {
+		assert.Equal(t, "req_api_1", reqErr.RequestID())
+		assert.Contains(t, err.Error(), "client_request_id=")
+	}
+}
@@ -0,0 +1,78 @@
+package cryptomepay
+
+import (
+	"context"
+	"fmt"
+)
+
+// ResendWebhookData describes a queued webhook redelivery
+type ResendWebhookData struct {
+	TradeID string `json:"trade_id"`
+
+	// NotifyURL is the callback URL the webhook is sent to
+	NotifyURL string `json:"notify_url"`
+}
+
+// ResendWebhookResponse is the API response for ResendWebhook
+type ResendWebhookResponse struct {
+	StatusCode int                `json:"status_code"`
+	Message    string             `json:"message"`
+	Data       *ResendWebhookData `json:"data"`
+	RequestID  string             `json:"request_id"`
+
+	ResponseMeta
+}
+
+// ResendWebhook asks the gateway to deliver the webhook of a paid or
+// expired order again, for example after the notify URL was down when the
+// payment confirmed. The redelivery is verified like the original one, so
+// webhook handlers should already treat repeated deliveries as duplicates.
+//
+// The order is queried first: a pending order has sent no webhook and fails
+// with ErrNoWebhookToResend. The request is signed like CreatePayment. It
+// is only retried when a key is passed with WithIdempotencyKey, since every
+// attempt may cause another delivery.
+func (c *Client) ResendWebhook(tradeID string, opts ...RequestOption) (*ResendWebhookResponse, error) {
+	ctx := context.Background()
+
+	if tradeID == "" {
+		return nil, &ValidationError{Field: "trade_id", Message: "is required"}
+	}
+
+	order, err := c.queryOrder(ctx, "trade_id", tradeID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if !order.Data.Status.IsTerminal() {
+		return nil, fmt.Errorf("%w: order %s is %s", ErrNoWebhookToResend, tradeID, order.Data.Status)
+	}
+
+	nonce, err := c.nonce()
+	if err != nil {
+		return nil, err
+	}
+
+	creds := c.credentials()
+	paramsMap := map[string]string{
+		"api_key":   creds.apiKey,
+		"timestamp": c.timestamp(),
+		"nonce":     nonce,
+		"trade_id":  tradeID,
+	}
+
+	signature, err := c.generateSignature(creds, paramsMap)
+	if err != nil {
+		return nil, err
+	}
+
+	body := make(map[string]interface{}, len(paramsMap)+1)
+	for k, v := range paramsMap {
+		body[k] = v
+	}
+	body["signature"] = signature
+
+	var resp ResendWebhookResponse
+	opts = append([]RequestOption{withAPIKey(creds.apiKey)}, opts...)
+	err = c.request(ctx, "POST", "/order/resend-notify", body, &resp, opts...)
+	return &resp, err
+}
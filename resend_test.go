@@ -0,0 +1,75 @@
+package cryptomepay
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// resendServer serves order with the query endpoint and records resend bodies
+func resendServer(t *testing.T, order *OrderData, resends *[]map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/merchant/order/query":
+			json.NewEncoder(w).Encode(OrderResponse{StatusCode: 200, Data: order})
+		case "/order/resend-notify":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			*resends = append(*resends, body)
+			json.NewEncoder(w).Encode(ResendWebhookResponse{StatusCode: 200, Data: &ResendWebhookData{TradeID: body["trade_id"], NotifyURL: "https://example.com/webhook"}})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+}
+
+func TestResendWebhook(t *testing.T) {
+	for _, status := range []PaymentStatus{StatusPaid, StatusExpired} {
+		var resends []map[string]string
+		server := resendServer(t, &OrderData{TradeID: "CP1", Status: status}, &resends)
+
+		client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+		resp, err := client.ResendWebhook("CP1")
+		assert.NoError(t, err)
+		assert.Equal(t, "https://example.com/webhook", resp.Data.NotifyURL)
+
+		if assert.Len(t, resends, 1) {
+			body := resends[0]
+			assert.Equal(t, "CP1", body["trade_id"])
+			assert.NotEmpty(t, body["nonce"])
+
+			signature := body["signature"]
+			delete(body, "signature")
+			assert.Equal(t, client.calculateSignature(body), signature)
+		}
+		server.Close()
+	}
+}
+
+func TestResendWebhookErrors(t *testing.T) {
+	var resends []map[string]string
+	server := resendServer(t, &OrderData{TradeID: "CP1", Status: StatusPending}, &resends)
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+	_, err := client.ResendWebhook("CP1")
+	assert.ErrorIs(t, err, ErrNoWebhookToResend)
+	assert.ErrorContains(t, err, "order CP1 is pending")
+
+	_, err = client.ResendWebhook("")
+	var validationErr *ValidationError
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, "trade_id", validationErr.Field)
+	}
+	assert.Empty(t, resends)
+
+	// Unknown orders are reported from the query
+	missing := resendServer(t, nil, &resends)
+	defer missing.Close()
+	_, err = client.ResendWebhook("CP_MISSING", WithRequestBaseURL(missing.URL))
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+	assert.Empty(t, resends)
+}
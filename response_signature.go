@@ -0,0 +1,39 @@
+package cryptomepay
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// DefaultResponseSignatureHeader is the response header the gateway uses for
+// its body signature: the lowercase hex HMAC-SHA256 of the raw response body,
+// keyed with the API secret.
+const DefaultResponseSignatureHeader = "X-Signature"
+
+// ResponseSignatureError is returned when a response enabled for signature
+// verification is unsigned or its signature does not match the body
+type ResponseSignatureError struct {
+	Header    string
+	Signature string
+}
+
+func (e *ResponseSignatureError) Error() string {
+	if e.Signature == "" {
+		return fmt.Sprintf("cryptomepay: response is missing the %s signature header", e.Header)
+	}
+	return fmt.Sprintf("cryptomepay: response signature in %s does not match the body", e.Header)
+}
+
+// verifyResponseSignature checks the HMAC in the configured header against
+// the raw body. It is a no-op when verification is not enabled.
+func (c *Client) verifyResponseSignature(resp *http.Response, body []byte) error {
+	if c.responseSignatureHeader == "" {
+		return nil
+	}
+
+	signature := resp.Header.Get(c.responseSignatureHeader)
+	if signature == "" || !hmacEqual(signHMAC(c.credentials().apiSecret, string(body)), signature) {
+		return &ResponseSignatureError{Header: c.responseSignatureHeader, Signature: signature}
+	}
+	return nil
+}
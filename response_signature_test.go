@@ -0,0 +1,65 @@
+package cryptomepay
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseSignatureVerification(t *testing.T) {
+	const body = `{"status_code":200,"message":"success","data":{"name":"Shop"}}`
+
+	tests := []struct {
+		name      string
+		signature string
+		body      string
+		wantErr   bool
+	}{
+		{"valid", signHMAC("test_secret", body), body, false},
+		{"tampered body", signHMAC("test_secret", body), `{"status_code":200,"message":"success","data":{"name":"Evil"}}`, true},
+		{"missing header", "", body, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.signature != "" {
+					w.Header().Set("X-Signature", tt.signature)
+				}
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClientWithOptions("sk_test_key", "test_secret",
+				WithBaseURL(server.URL),
+				WithResponseSignatureVerification(""),
+			)
+
+			resp, err := client.GetMerchantInfo()
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				assert.Equal(t, "Shop", resp.Data.Name)
+				return
+			}
+
+			var sigErr *ResponseSignatureError
+			assert.ErrorAs(t, err, &sigErr)
+			assert.Equal(t, "X-Signature", sigErr.Header)
+			assert.Nil(t, resp.Data)
+		})
+	}
+}
+
+func TestResponseSignatureVerificationDisabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status_code":200,"data":{"name":"Shop"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	_, err := client.GetMerchantInfo()
+	assert.NoError(t, err)
+}
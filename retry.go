@@ -0,0 +1,208 @@
+package cryptomepay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// WithRetry retries a failed call up to maxAttempts attempts in total,
+// waiting baseDelay, then twice that, and so on, each with random jitter.
+// The doubling saturates rather than overflowing, so many attempts end up
+// waiting a very long time, not none; cap it with WithMaxRetryDelay.
+//
+// Only retryable API errors (see APIError.IsRetryable) and transient
+// network failures such as connection resets and timeouts are retried. A
+// POST is retried only when it carries an idempotency key
+// (WithIdempotencyKey); CreatePayment always sends one, reused by every
+// attempt. Each attempt resends the same body, so a signed request is
+// replayed with the nonce, timestamp and signature of the first attempt.
+// No retry is started that the call's context deadline would cut short. A
+// call canceled while waiting to retry fails with an error matching both
+// ctx.Err() and the last attempt's error. The number of attempts is
+// reported in ResponseMeta.
+func WithRetry(maxAttempts int, baseDelay time.Duration) Option {
+	return func(c *Client) {
+		c.maxAttempts = maxAttempts
+		c.retryBaseDelay = baseDelay
+	}
+}
+
+// Jitter selects how retry delays are randomized, see WithRetryJitter
+type Jitter int
+
+const (
+	// EqualJitter waits between half and all of the exponential delay
+	EqualJitter Jitter = iota
+
+	// FullJitter waits between zero and the whole exponential delay, which
+	// spreads the retries of many clients the most
+	FullJitter
+)
+
+// WithMaxRetryDelay caps the exponential delay between attempts at d
+// before jitter is applied. A d <= 0 leaves it uncapped, the default.
+func WithMaxRetryDelay(d time.Duration) Option {
+	return func(c *Client) {
+		c.retryMaxDelay = d
+	}
+}
+
+// WithRetryJitter sets how delays between attempts are randomized. The
+// default is EqualJitter; FullJitter keeps a fleet of clients from retrying
+// in step after an outage.
+func WithRetryJitter(jitter Jitter) Option {
+	return func(c *Client) {
+		c.retryJitter = jitter
+	}
+}
+
+// WithRetryAfter makes a retry after an *APIError with a RetryAfter, such
+// as a 429 carrying a Retry-After header, wait exactly that long instead of
+// the backoff delay. WithMaxRetryDelay does not shorten it, but no retry is
+// started that the call's context deadline would cut short.
+func WithRetryAfter(honor bool) Option {
+	return func(c *Client) {
+		c.honorRetryAfter = honor
+	}
+}
+
+// retry runs do until it succeeds, fails permanently or the attempts are
+// used up, and returns the number of attempts made. result is reset before
+// each retry so no fields of a failed response carry over.
+func (c *Client) retry(ctx context.Context, method string, ro *requestOptions, do func(context.Context) error, result interface{}) (int, error) {
+	maxAttempts := c.maxAttempts
+	if maxAttempts < 1 || (method != http.MethodGet && ro.idempotencyKey == "") {
+		maxAttempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := do(ctx)
+		if err == nil || attempt >= maxAttempts || !isRetryable(ctx, err) {
+			return attempt, err
+		}
+
+		delay := backoff(c.retryBaseDelay, c.retryMaxDelay, c.retryJitter, attempt)
+		var apiErr *APIError
+		if c.honorRetryAfter && errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+			delay = apiErr.RetryAfter
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return attempt, err
+		}
+
+		c.logger.Log(ctx, LevelWarn, "cryptomepay: retrying request",
+			"method", method, "attempt", attempt, "delay", delay, "error", err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			// Match both the cancellation and the failure that was retried
+			timer.Stop()
+			return attempt, fmt.Errorf("%w, last attempt: %w", ctx.Err(), err)
+		case <-timer.C:
+		}
+
+		resetResult(result)
+	}
+}
+
+// isRetryable reports whether err is worth another attempt. Nothing is
+// retried once the call's own context is done.
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.IsRetryable()
+	}
+
+	// A per-attempt timeout surfaces as a net.Error timeout
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
+
+// backoff returns the delay before the retry following attempt: the
+// exponentialDelay d with jitter in [d/2, d], or in [0, d] for FullJitter
+func backoff(base, maxDelay time.Duration, jitter Jitter, attempt int) time.Duration {
+	d := exponentialDelay(base, maxDelay, attempt)
+	if d == 0 {
+		return 0
+	}
+	if jitter == FullJitter {
+		if d == math.MaxInt64 {
+			return time.Duration(rand.Int63())
+		}
+		return time.Duration(rand.Int63n(int64(d) + 1))
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// exponentialDelay is base doubled per attempt, saturating at the largest
+// Duration, and capped at maxDelay if positive
+func exponentialDelay(base, maxDelay time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+
+	// Saturate instead of letting the shift overflow
+	d := time.Duration(math.MaxInt64)
+	if shift := attempt - 1; shift < 63 && base <= d>>shift {
+		d = base << shift
+	}
+	if maxDelay > 0 && d > maxDelay {
+		d = maxDelay
+	}
+	return d
+}
+
+// parseRetryAfter returns the delay a Retry-After header asks for, given
+// in seconds or as an HTTP date, and 0 when it is absent, invalid or past.
+// A date is measured from the response's Date header when it has one, so
+// a skewed local clock does not change the delay, and from now otherwise.
+func parseRetryAfter(header http.Header, now time.Time) time.Duration {
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	retryAt, err := http.ParseTime(value)
+	if err != nil {
+		return 0
+	}
+	if date, err := http.ParseTime(header.Get("Date")); err == nil {
+		now = date
+	}
+	if delay := retryAt.Sub(now); delay > 0 {
+		return delay
+	}
+	return 0
+}
+
+// resetResult zeroes the value result points to
+func resetResult(result interface{}) {
+	if v := reflect.ValueOf(result); v.Kind() == reflect.Pointer && !v.IsNil() {
+		v.Elem().Set(reflect.Zero(v.Elem().Type()))
+	}
+}
@@ -0,0 +1,391 @@
+package cryptomepay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyServer fails the first failures requests with status, then succeeds
+func flakyServer(failures int32, status int, calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(calls, 1) <= failures {
+			w.WriteHeader(status)
+			fmt.Fprintf(w, `{"status_code":%d,"message":"try again"}`, status)
+			return
+		}
+		json.NewEncoder(w).Encode(MerchantResponse{StatusCode: 200, Data: &MerchantData{Name: "Shop"}})
+	}))
+}
+
+func TestRetryRetryableErrors(t *testing.T) {
+	var calls int32
+	server := flakyServer(2, http.StatusServiceUnavailable, &calls)
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithRetry(3, time.Millisecond),
+	)
+
+	resp, err := client.GetMerchantInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "Shop", resp.Data.Name)
+	assert.Equal(t, "", resp.Message)
+	assert.Equal(t, 3, resp.Attempts)
+	assert.Equal(t, int32(3), calls)
+}
+
+func TestRetryStopsOnNonRetryableError(t *testing.T) {
+	var calls int32
+	server := flakyServer(5, http.StatusBadRequest, &calls)
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithRetry(3, time.Millisecond),
+	)
+
+	resp, err := client.GetMerchantInfo()
+	assert.Error(t, err)
+	assert.Equal(t, 1, resp.Attempts)
+	assert.Equal(t, int32(1), calls)
+}
+
+func TestRetryStopsOnBusinessErrorCodes(t *testing.T) {
+	// Business codes are above 500 but are not server errors, whatever the
+	// HTTP status they come with
+	for _, httpStatus := range []int{http.StatusOK, http.StatusBadRequest, http.StatusInternalServerError} {
+		for _, code := range []int{ErrCodeOrderExists, ErrCodeInvalidAPIKey} {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.WriteHeader(httpStatus)
+				fmt.Fprintf(w, `{"status_code":%d,"message":"rejected"}`, code)
+			}))
+
+			client := NewClientWithOptions("sk_test_key", "test_secret",
+				WithBaseURL(server.URL),
+				WithRetry(4, time.Millisecond),
+			)
+			_, err := client.GetMerchantInfo()
+			assert.Error(t, err)
+			assert.Equal(t, int32(1), calls, "code %d over HTTP %d", code, httpStatus)
+			server.Close()
+		}
+	}
+
+	// The gateway's rate limit codes are retried even over HTTP 200
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			fmt.Fprintf(w, `{"status_code":%d,"message":"slow down"}`, ErrCodeBurstLimitExceeded)
+			return
+		}
+		json.NewEncoder(w).Encode(MerchantResponse{StatusCode: 200, Data: &MerchantData{Name: "Shop"}})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL), WithRetry(4, time.Millisecond))
+	resp, err := client.GetMerchantInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, resp.Attempts)
+}
+
+func TestRetryPOSTRequiresIdempotencyKey(t *testing.T) {
+	var calls int32
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		json.NewEncoder(w).Encode(PaymentResponse{StatusCode: 200, Data: &PaymentData{TradeID: "CP1"}})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithRetry(3, time.Millisecond),
+	)
+	params := &CreatePaymentParams{OrderID: "ORDER_001", Amount: 1, NotifyURL: "https://example.com/webhook"}
+
+	// A POST without an idempotency key is sent once
+	bulk, err := client.BulkCreatePayments(context.Background(), []*CreatePaymentParams{params})
+	assert.Error(t, err)
+	assert.Equal(t, 1, bulk.Attempts)
+
+	atomic.StoreInt32(&calls, 0)
+	keys = nil
+
+	resp, err := client.CreatePayment(params, WithIdempotencyKey("key-1"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, resp.Attempts)
+	assert.Equal(t, []string{"key-1", "key-1"}, keys)
+}
+
+func TestRetryAfterPerAttemptTimeout(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			// The first attempt hangs until the client gives up on it
+			<-r.Context().Done()
+			return
+		}
+		json.NewEncoder(w).Encode(MerchantResponse{StatusCode: 200, Data: &MerchantData{Name: "Shop"}})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithPerAttemptTimeout(50*time.Millisecond),
+		WithRetry(2, time.Millisecond),
+	)
+
+	resp, err := client.GetMerchantInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "Shop", resp.Data.Name)
+	assert.Equal(t, 2, resp.Attempts)
+}
+
+func TestRetryRespectsContextDeadline(t *testing.T) {
+	var calls int32
+	server := flakyServer(5, http.StatusServiceUnavailable, &calls)
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithRetry(5, time.Second),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.QueryPayment(ctx, QueryParams{TradeID: "CP1"})
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), calls)
+	assert.Less(t, time.Since(start), 150*time.Millisecond)
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := 1; attempt <= 4; attempt++ {
+		max := 100 * time.Millisecond << (attempt - 1)
+		for i := 0; i < 20; i++ {
+			d := backoff(100*time.Millisecond, 0, EqualJitter, attempt)
+			assert.GreaterOrEqual(t, d, max/2)
+			assert.LessOrEqual(t, d, max)
+		}
+	}
+}
+
+func TestBackoffCapAndJitter(t *testing.T) {
+	var belowHalf bool
+	for i := 0; i < 200; i++ {
+		d := backoff(100*time.Millisecond, 250*time.Millisecond, EqualJitter, 5)
+		assert.GreaterOrEqual(t, d, 125*time.Millisecond)
+		assert.LessOrEqual(t, d, 250*time.Millisecond)
+
+		d = backoff(100*time.Millisecond, 250*time.Millisecond, FullJitter, 5)
+		assert.GreaterOrEqual(t, d, time.Duration(0))
+		assert.LessOrEqual(t, d, 250*time.Millisecond)
+		belowHalf = belowHalf || d < 125*time.Millisecond
+	}
+	assert.True(t, belowHalf, "full jitter spans the whole delay")
+
+	// The cap also holds once the doubling overflows
+	assert.LessOrEqual(t, backoff(time.Second, time.Minute, EqualJitter, 80), time.Minute)
+}
+
+func TestBackoffSaturates(t *testing.T) {
+	// One second doubled 33 times still fits in a Duration
+	assert.Equal(t, time.Second<<33, exponentialDelay(time.Second, 0, 34))
+
+	// Without a cap, delays past the int64 range saturate instead of
+	// wrapping to zero or a negative duration
+	for _, attempt := range []int{35, 62, 64, 80, 1000} {
+		assert.Equal(t, time.Duration(math.MaxInt64), exponentialDelay(time.Second, 0, attempt), "attempt %d", attempt)
+
+		d := backoff(time.Second, 0, EqualJitter, attempt)
+		assert.GreaterOrEqual(t, d, time.Duration(math.MaxInt64/2), "attempt %d", attempt)
+		d = backoff(time.Second, 0, FullJitter, attempt)
+		assert.GreaterOrEqual(t, d, time.Duration(0), "attempt %d", attempt)
+	}
+
+	// 9ns << 61 keeps a positive but wrapped value if only the sign is checked
+	assert.Equal(t, time.Duration(math.MaxInt64), exponentialDelay(9, 0, 62))
+	assert.Equal(t, time.Minute, exponentialDelay(time.Second, time.Minute, 1000))
+	assert.Zero(t, backoff(0, 0, EqualJitter, 3))
+}
+
+func TestRetryReusesSignedBody(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(PaymentResponse{StatusCode: 200, Data: &PaymentData{TradeID: "CP1"}})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithRetry(2, time.Millisecond),
+	)
+
+	_, err := client.CreatePayment(&CreatePaymentParams{OrderID: "ORDER_001", Amount: 1, NotifyURL: "https://example.com/webhook"})
+	assert.NoError(t, err)
+	if assert.Len(t, bodies, 2) {
+		for _, field := range []string{"nonce", "timestamp", "signature"} {
+			assert.NotEmpty(t, bodies[0][field])
+			assert.Equal(t, bodies[0][field], bodies[1][field], field)
+		}
+	}
+}
+
+func TestRetryHonorsRetryAfter(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"status_code":50001,"message":"rate limit exceeded"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(MerchantResponse{StatusCode: 200, Data: &MerchantData{Name: "Shop"}})
+	}))
+	defer server.Close()
+
+	// Retry-After replaces the much longer backoff
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithRetry(2, time.Hour),
+		WithRetryAfter(true),
+	)
+	start := time.Now()
+	resp, err := client.GetMerchantInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, resp.Attempts)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	// Without retries the delay is still reported
+	atomic.StoreInt32(&calls, 0)
+	_, err = client.Clone(WithRetry(1, 0)).GetMerchantInfo()
+	var apiErr *APIError
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, time.Second, apiErr.RetryAfter)
+		assert.ErrorIs(t, err, ErrRateLimitExceeded)
+	}
+}
+
+func TestRetryKeepsGeneratedOrderID(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(PaymentResponse{StatusCode: 200, Data: &PaymentData{TradeID: "CP1"}})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithRetry(2, time.Millisecond),
+		WithAutoOrderID(func() string { return "AUTO_1" }),
+	)
+
+	resp, err := client.CreatePayment(&CreatePaymentParams{Amount: 1, NotifyURL: "https://example.com/webhook"},
+		WithIdempotencyKey("key-1"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, resp.Attempts)
+	assert.Equal(t, "AUTO_1", resp.GeneratedOrderID)
+}
+
+func TestCreatePaymentIdempotencyKey(t *testing.T) {
+	var keys []string
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if atomic.AddInt32(&calls, 1)%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(PaymentResponse{StatusCode: 200, Data: &PaymentData{TradeID: "CP1"}})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithRetry(2, time.Millisecond),
+	)
+
+	// Without a key one is derived from the order id, so the retry is safe
+	params := &CreatePaymentParams{OrderID: "ORDER_001", Amount: 1, NotifyURL: "https://example.com/webhook"}
+	resp, err := client.CreatePayment(params)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, resp.Attempts)
+
+	params.IdempotencyKey = "checkout-42"
+	_, err = client.CreatePayment(params)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"order-ORDER_001", "order-ORDER_001", "checkout-42", "checkout-42"}, keys)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 12, 1, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+	}{
+		{"absent", http.Header{}, 0},
+		{"seconds", http.Header{"Retry-After": {"120"}}, 2 * time.Minute},
+		{"zero seconds", http.Header{"Retry-After": {"0"}}, 0},
+		{"negative seconds", http.Header{"Retry-After": {"-5"}}, 0},
+		{"http date", http.Header{"Retry-After": {"Mon, 01 Dec 2025 10:30:45 GMT"}}, 45 * time.Second},
+		{"past http date", http.Header{"Retry-After": {"Mon, 01 Dec 2025 10:29:00 GMT"}}, 0},
+		{"http date from server date", http.Header{
+			"Retry-After": {"Mon, 01 Dec 2025 11:00:30 GMT"},
+			"Date":        {"Mon, 01 Dec 2025 11:00:00 GMT"},
+		}, 30 * time.Second},
+		{"invalid", http.Header{"Retry-After": {"soon"}}, 0},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, parseRetryAfter(tt.header, now), tt.name)
+	}
+}
+
+func TestRetryAfterHTTPDate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		now := time.Now().UTC()
+		w.Header().Set("Date", now.Format(http.TimeFormat))
+		w.Header().Set("Retry-After", now.Add(90*time.Second).Format(http.TimeFormat))
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"status_code":50002,"message":"burst limit exceeded"}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+	_, err := client.GetMerchantInfo()
+	var apiErr *APIError
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, http.StatusTooManyRequests, apiErr.HTTPStatus)
+		assert.Equal(t, 90*time.Second, apiErr.RetryAfter)
+	}
+}
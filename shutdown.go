@@ -0,0 +1,103 @@
+package cryptomepay
+
+import (
+	"context"
+	"sync"
+)
+
+// lifecycle tracks in-flight requests so Shutdown can drain them
+type lifecycle struct {
+	mu       sync.Mutex
+	closed   bool
+	inFlight int
+	drained  chan struct{}
+}
+
+// begin registers a request, failing once the client is shut down
+func (l *lifecycle) begin() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return ErrClientClosed
+	}
+	l.inFlight++
+	return nil
+}
+
+// end unregisters a request and wakes Shutdown when the last one finishes
+func (l *lifecycle) end() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	if l.closed && l.inFlight == 0 && l.drained != nil {
+		close(l.drained)
+		l.drained = nil
+	}
+}
+
+// Shutdown stops the client from starting new requests, which then fail
+// with ErrClientClosed, and waits for in-flight requests to finish. Idle
+// connections are closed once they have drained. If ctx expires first, the
+// number of requests still in flight is returned along with ctx.Err().
+func (c *Client) Shutdown(ctx context.Context) (int, error) {
+	l := c.lifecycle
+
+	l.mu.Lock()
+	l.closed = true
+	if l.inFlight == 0 {
+		l.mu.Unlock()
+		c.httpClient.CloseIdleConnections()
+		return 0, nil
+	}
+	if l.drained == nil {
+		l.drained = make(chan struct{})
+	}
+	drained := l.drained
+	l.mu.Unlock()
+
+	select {
+	case <-drained:
+		c.httpClient.CloseIdleConnections()
+		return 0, nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		remaining := l.inFlight
+		l.mu.Unlock()
+		return remaining, ctx.Err()
+	}
+}
+
+// Close stops the client from starting new requests, which then fail with
+// ErrClientClosed, and closes its idle connections without waiting for
+// in-flight requests; connections they hold are closed once they finish.
+// Use Shutdown to wait for them instead. Close may be called more than once
+// and always returns nil, so the client can be used as an io.Closer.
+//
+// The connections belong to the HTTP client's transport, which is shared
+// with clones and, for a client from WithHTTPClient or WithTransport, with
+// whoever else uses it; Close closes its idle connections for all of them,
+// and they open new ones as needed. Without either option the transport is
+// http.DefaultTransport.
+func (c *Client) Close() error {
+	l := c.lifecycle
+
+	l.mu.Lock()
+	l.closed = true
+	var drained chan struct{}
+	if l.inFlight > 0 {
+		if l.drained == nil {
+			l.drained = make(chan struct{})
+		}
+		drained = l.drained
+	}
+	l.mu.Unlock()
+
+	c.httpClient.CloseIdleConnections()
+	if drained != nil {
+		go func() {
+			<-drained
+			c.httpClient.CloseIdleConnections()
+		}()
+	}
+	return nil
+}
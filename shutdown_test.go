@@ -0,0 +1,108 @@
+package cryptomepay
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShutdownDrainsInFlight(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		json.NewEncoder(w).Encode(MerchantResponse{StatusCode: 200})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	done := make(chan error)
+	go func() {
+		_, err := client.GetMerchantInfo()
+		done <- err
+	}()
+	<-started
+
+	// Times out while the request is still running
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	remaining, err := client.Shutdown(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, remaining)
+
+	// New requests are rejected
+	_, err = client.GetMerchantInfo()
+	assert.ErrorIs(t, err, ErrClientClosed)
+
+	// Drains once the request completes
+	close(release)
+	remaining, err = client.Shutdown(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, remaining)
+	assert.NoError(t, <-done)
+}
+
+func TestShutdownIdle(t *testing.T) {
+	client := NewClient("sk_test_key", "test_secret")
+
+	remaining, err := client.Shutdown(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, remaining)
+
+	// Clones keep working after the original is shut down
+	clone := client.Clone()
+	assert.NoError(t, clone.lifecycle.begin())
+}
+
+func TestClose(t *testing.T) {
+	var closed int32
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		if r.Header.Get("X-Wait") != "" {
+			<-release
+		}
+		json.NewEncoder(w).Encode(MerchantResponse{StatusCode: 200})
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			atomic.AddInt32(&closed, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL), WithTransport(&http.Transport{}))
+
+	// One connection held by a request in flight, and one idle
+	done := make(chan error)
+	go func() {
+		_, err := client.GetMerchantInfo(WithRequestHeader("X-Wait", "1"))
+		done <- err
+	}()
+	<-started
+	_, err := client.GetMerchantInfo()
+	assert.NoError(t, err)
+	<-started
+
+	assert.NoError(t, client.Close())
+	assert.NoError(t, client.Close())
+	_, err = client.GetMerchantInfo()
+	assert.ErrorIs(t, err, ErrClientClosed)
+
+	// The idle connection closes at once, the busy one after its request
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&closed) == 1 }, time.Second, 5*time.Millisecond)
+	close(release)
+	assert.NoError(t, <-done)
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&closed) == 2 }, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,51 @@
+package cryptomepay
+
+// Signer signs request parameters and computes the expected signature of
+// webhooks. The default signs the SigningString with HMAC-SHA256 keyed by
+// the api secret.
+type Signer interface {
+	// SignType is sent as the signed sign_type parameter of each request.
+	// An empty SignType sends no sign_type, as the original scheme expects.
+	SignType() string
+
+	// Sign returns the signature of params, which exclude "signature"
+	Sign(params map[string]string) (string, error)
+}
+
+// HMACSigner signs the SigningString with HMAC-SHA256 keyed by Secret
+type HMACSigner struct {
+	Secret string
+
+	// Type is returned by SignType, empty for the original scheme
+	Type string
+}
+
+// SignType returns s.Type
+func (s HMACSigner) SignType() string {
+	return s.Type
+}
+
+// Sign returns the hex HMAC-SHA256 of SigningString(params)
+func (s HMACSigner) Sign(params map[string]string) (string, error) {
+	return signHMAC(s.Secret, SigningString(params)), nil
+}
+
+// WithSigner signs requests and verifies webhooks with s instead of
+// HMAC-SHA256 over the api secret, for example to opt into a newer
+// signature scheme or to sign with a key held in an HSM. Response
+// signatures (see WithResponseSignatureVerification) are not affected. A
+// nil s restores the default.
+func WithSigner(s Signer) Option {
+	return func(c *Client) {
+		c.signer = s
+	}
+}
+
+// requestSigner returns the configured Signer or the default one for
+// apiSecret
+func (c *Client) requestSigner(apiSecret string) Signer {
+	if c.signer != nil {
+		return c.signer
+	}
+	return HMACSigner{Secret: apiSecret}
+}
@@ -0,0 +1,81 @@
+package cryptomepay
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// upperSigner is a stand-in for another signature scheme
+type upperSigner struct {
+	err error
+}
+
+func (upperSigner) SignType() string { return "TEST-V2" }
+
+func (s upperSigner) Sign(params map[string]string) (string, error) {
+	return strings.ToUpper(signHMAC("v2_secret", SigningString(params))), s.err
+}
+
+func TestWithSignerRequests(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		json.NewDecoder(r.Body).Decode(&body)
+		json.NewEncoder(w).Encode(PaymentResponse{StatusCode: 200, Data: &PaymentData{TradeID: "CP1"}})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL), WithSigner(upperSigner{}))
+	_, err := client.CreatePayment(&CreatePaymentParams{OrderID: "ORDER_001", Amount: 100, NotifyURL: "https://example.com/webhook"})
+	assert.NoError(t, err)
+
+	// sign_type is sent and covered by the signature
+	assert.Equal(t, "TEST-V2", body["sign_type"])
+	signed := map[string]string{"amount": "100.00"}
+	for _, k := range []string{"api_key", "timestamp", "nonce", "order_id", "notify_url", "sign_type"} {
+		signed[k] = body[k].(string)
+	}
+	expected, _ := upperSigner{}.Sign(signed)
+	assert.Equal(t, expected, body["signature"])
+
+	// Without a custom signer no sign_type is sent
+	client = client.Clone(WithSigner(nil))
+	_, err = client.CreatePayment(&CreatePaymentParams{OrderID: "ORDER_002", Amount: 100, NotifyURL: "https://example.com/webhook"})
+	assert.NoError(t, err)
+	assert.NotContains(t, body, "sign_type")
+}
+
+func TestWithSignerError(t *testing.T) {
+	failure := errors.New("hsm unavailable")
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithSigner(upperSigner{err: failure}))
+
+	_, err := client.CreatePayment(&CreatePaymentParams{OrderID: "ORDER_001", Amount: 100, NotifyURL: "https://example.com/webhook"})
+	assert.ErrorIs(t, err, failure)
+	_, err = client.CancelOrder("CP1")
+	assert.ErrorIs(t, err, failure)
+}
+
+func TestWithSignerWebhooks(t *testing.T) {
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithSigner(upperSigner{}))
+	signature, _ := upperSigner{}.Sign(map[string]string{"trade_id": "CP1", "status": "2"})
+
+	assert.True(t, client.VerifyWebhookSignature(&WebhookPayload{TradeID: "CP1", Status: StatusPaid, Signature: signature}))
+
+	// An HMAC signature over the api secret no longer verifies
+	hmacSignature := HMACSigner{Secret: "test_secret"}
+	legacy, _ := hmacSignature.Sign(map[string]string{"trade_id": "CP1", "status": "2"})
+	assert.False(t, client.VerifyWebhookSignature(&WebhookPayload{TradeID: "CP1", Status: StatusPaid, Signature: legacy}))
+
+	// A sign_type delivered with the webhook is part of the signed fields
+	signature, _ = upperSigner{}.Sign(map[string]string{"trade_id": "CP1", "status": "2", "sign_type": "TEST-V2"})
+	assert.True(t, client.VerifyWebhookSignature(&WebhookPayload{TradeID: "CP1", Status: StatusPaid, SignType: "TEST-V2", Signature: signature}))
+	assert.True(t, client.VerifyWebhookSignatureFromMap(map[string]interface{}{
+		"trade_id": "CP1", "status": "2", "sign_type": "TEST-V2", "signature": signature,
+	}))
+}
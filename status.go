@@ -0,0 +1,125 @@
+package cryptomepay
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// PaymentStatus is the state of an order. It is sent and received as the
+// integer status code, see StatusPending, StatusPaid and StatusExpired.
+type PaymentStatus int
+
+// String returns "pending", "paid" or "expired", and PaymentStatus(n) for
+// values the SDK does not know
+func (s PaymentStatus) String() string {
+	switch s {
+	case StatusPending:
+		return "pending"
+	case StatusPaid:
+		return "paid"
+	case StatusExpired:
+		return "expired"
+	}
+	return fmt.Sprintf("PaymentStatus(%d)", int(s))
+}
+
+// IsTerminal reports whether the order can no longer change state
+func (s PaymentStatus) IsTerminal() bool {
+	return s == StatusPaid || s == StatusExpired
+}
+
+// MarshalJSON encodes the status as its integer code
+func (s PaymentStatus) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Itoa(int(s))), nil
+}
+
+// UnmarshalJSON decodes the integer code, also accepting it as a quoted
+// string
+func (s *PaymentStatus) UnmarshalJSON(b []byte) error {
+	v := strings.TrimSpace(string(b))
+	if v == "null" {
+		return nil
+	}
+	if strings.HasPrefix(v, `"`) {
+		unquoted, err := strconv.Unquote(v)
+		if err != nil {
+			return fmt.Errorf("invalid status %s: %w", v, err)
+		}
+		v = strings.TrimSpace(unquoted)
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("invalid status %s: %w", v, err)
+	}
+	*s = PaymentStatus(n)
+	return nil
+}
+
+// IsPartiallyPaid reports whether some, but not all, of ActualAmount has
+// arrived for a pending or expired order. It is always false when the
+// gateway does not report AmountReceived. Do not release goods for such an
+// order; Shortfall gives the amount still missing.
+func (d *OrderData) IsPartiallyPaid() bool {
+	return d.Status != StatusPaid && receivedDiff(d.ActualAmount, d.AmountReceived) < 0
+}
+
+// Shortfall returns how much of ActualAmount has not arrived for a
+// partially paid order, rounded to ActualAmountDecimals, and 0 otherwise
+func (d *OrderData) Shortfall() float64 {
+	if !d.IsPartiallyPaid() {
+		return 0
+	}
+	return fromActualUnits(-receivedDiff(d.ActualAmount, d.AmountReceived))
+}
+
+// IsOverpaid reports whether more than ActualAmount has arrived, for
+// example to start a refund of the Excess. It is always false when the
+// gateway does not report AmountReceived.
+func (d *OrderData) IsOverpaid() bool {
+	return receivedDiff(d.ActualAmount, d.AmountReceived) > 0
+}
+
+// Excess returns how much more than ActualAmount has arrived, rounded to
+// ActualAmountDecimals, and 0 unless the order is overpaid
+func (d *OrderData) Excess() float64 {
+	return excess(d.ActualAmount, d.AmountReceived)
+}
+
+// IsPartiallyPaid is OrderData.IsPartiallyPaid for a webhook delivery
+func (p *WebhookPayload) IsPartiallyPaid() bool {
+	return p.Status != StatusPaid && receivedDiff(p.ActualAmount, p.AmountReceived) < 0
+}
+
+// IsOverpaid is OrderData.IsOverpaid for a webhook delivery
+func (p *WebhookPayload) IsOverpaid() bool {
+	return receivedDiff(p.ActualAmount, p.AmountReceived) > 0
+}
+
+// Excess is OrderData.Excess for a webhook delivery
+func (p *WebhookPayload) Excess() float64 {
+	return excess(p.ActualAmount, p.AmountReceived)
+}
+
+// receivedDiff returns received minus actual in units of
+// ActualAmountDecimals, or 0 when no amount was reported received
+func receivedDiff(actual, received float64) int64 {
+	r := roundActualAmount(received)
+	if r == 0 {
+		return 0
+	}
+	return r - roundActualAmount(actual)
+}
+
+func excess(actual, received float64) float64 {
+	if diff := receivedDiff(actual, received); diff > 0 {
+		return fromActualUnits(diff)
+	}
+	return 0
+}
+
+func fromActualUnits(n int64) float64 {
+	return float64(n) / math.Pow10(ActualAmountDecimals)
+}
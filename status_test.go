@@ -0,0 +1,78 @@
+package cryptomepay
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentStatusString(t *testing.T) {
+	assert.Equal(t, "pending", StatusPending.String())
+	assert.Equal(t, "paid", StatusPaid.String())
+	assert.Equal(t, "expired", StatusExpired.String())
+	assert.Equal(t, "PaymentStatus(9)", PaymentStatus(9).String())
+}
+
+func TestPaymentStatusIsTerminal(t *testing.T) {
+	assert.False(t, StatusPending.IsTerminal())
+	assert.True(t, StatusPaid.IsTerminal())
+	assert.True(t, StatusExpired.IsTerminal())
+	assert.False(t, PaymentStatus(0).IsTerminal())
+}
+
+func TestPaymentStatusJSON(t *testing.T) {
+	b, err := json.Marshal(OrderData{TradeID: "CP1", Status: StatusPaid})
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"status":2`)
+
+	var order OrderData
+	assert.NoError(t, json.Unmarshal([]byte(`{"trade_id":"CP1","status":3}`), &order))
+	assert.Equal(t, StatusExpired, order.Status)
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"status":"1"}`), &order))
+	assert.Equal(t, StatusPending, order.Status)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"status":"paid"}`), &order))
+
+	// An unset filter is still omitted from list requests
+	b, err = json.Marshal(ListOrdersParams{Page: 1})
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "status")
+}
+
+func TestOrderPartiallyPaid(t *testing.T) {
+	var order OrderData
+	assert.NoError(t, json.Unmarshal([]byte(`{"trade_id":"CP1","status":1,"actual_amount":"14.5000","amount_received":"10.25"}`), &order))
+	assert.Equal(t, 10.25, order.AmountReceived)
+	assert.True(t, order.IsPartiallyPaid())
+	assert.Equal(t, 4.25, order.Shortfall())
+
+	// Expired underpaid orders still report the shortfall
+	order.Status = StatusExpired
+	assert.True(t, order.IsPartiallyPaid())
+
+	tests := []OrderData{
+		{Status: StatusPending, ActualAmount: 14.5},
+		{Status: StatusPending, ActualAmount: 14.5, AmountReceived: 14.5},
+		{Status: StatusPending, ActualAmount: 14.5, AmountReceived: 15},
+		{Status: StatusPaid, ActualAmount: 14.5, AmountReceived: 10},
+	}
+	for _, tt := range tests {
+		assert.False(t, tt.IsPartiallyPaid(), "%+v", tt)
+		assert.Zero(t, tt.Shortfall())
+	}
+}
+
+func TestOrderOverpaid(t *testing.T) {
+	order := OrderData{Status: StatusPaid, ActualAmount: 14.5, AmountReceived: 15.1234}
+	assert.True(t, order.IsOverpaid())
+	assert.Equal(t, 0.6234, order.Excess())
+	assert.False(t, order.IsPartiallyPaid())
+
+	for _, received := range []float64{0, 14.5, 14.49999, 10} {
+		order.AmountReceived = received
+		assert.False(t, order.IsOverpaid(), "%v", received)
+		assert.Zero(t, order.Excess())
+	}
+}
@@ -0,0 +1,186 @@
+package cryptomepay
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ChainInfo describes a chain as the gateway currently offers it
+type ChainInfo struct {
+	ChainType ChainType `json:"chain_type"`
+	Name      string    `json:"name"`
+
+	// Available is false while the gateway does not take new orders on the
+	// chain, for example during maintenance
+	Available bool `json:"available"`
+
+	// MinAmount and MaxAmount bound the order amount, 0 when unbounded
+	MinAmount float64 `json:"min_amount"`
+	MaxAmount float64 `json:"max_amount"`
+}
+
+// DisplayName returns the gateway's name for the chain, or
+// ChainType.DisplayName when it sent none
+func (i ChainInfo) DisplayName() string {
+	if i.Name != "" {
+		return i.Name
+	}
+	return i.ChainType.DisplayName()
+}
+
+// Accepts reports whether the chain is available for an order of amount
+func (i ChainInfo) Accepts(amount float64) bool {
+	return i.CheckAmount(amount) == nil
+}
+
+// CheckAmount returns a *ValidationError naming the limit when the chain is
+// unavailable or amount is outside MinAmount and MaxAmount
+func (i ChainInfo) CheckAmount(amount float64) error {
+	switch {
+	case !i.Available:
+		return &ValidationError{Field: "chain_type", Message: fmt.Sprintf("%s is not available", i.ChainType)}
+	case amount < i.MinAmount:
+		return &ValidationError{Field: "amount", Message: fmt.Sprintf("%s is below the %s minimum of %s", formatAmount(amount), i.ChainType, formatAmount(i.MinAmount))}
+	case i.MaxAmount > 0 && amount > i.MaxAmount:
+		return &ValidationError{Field: "amount", Message: fmt.Sprintf("%s is above the %s maximum of %s", formatAmount(amount), i.ChainType, formatAmount(i.MaxAmount))}
+	}
+	return nil
+}
+
+// ChainListResponse is the API response for GetSupportedChains
+type ChainListResponse struct {
+	StatusCode int         `json:"status_code"`
+	Message    string      `json:"message"`
+	Data       []ChainInfo `json:"data"`
+	RequestID  string      `json:"request_id"`
+
+	ResponseMeta
+}
+
+// Chain returns the entry for chain, and false if the gateway did not list it
+func (r *ChainListResponse) Chain(chain ChainType) (ChainInfo, bool) {
+	for _, info := range r.Data {
+		if info.ChainType == chain {
+			return info, true
+		}
+	}
+	return ChainInfo{}, false
+}
+
+// copy returns r with its own Data, so callers cannot change a cached value
+func (r *ChainListResponse) copy() *ChainListResponse {
+	out := *r
+	out.Data = append([]ChainInfo(nil), r.Data...)
+	return &out
+}
+
+// WithSupportedChainsCache caches successful GetSupportedChains responses
+// per api key for ttl, like WithMerchantCache does for merchant info. Use
+// GetSupportedChainsFresh to bypass the cache. A ttl of 0 disables caching.
+func WithSupportedChainsCache(ttl time.Duration) Option {
+	return func(c *Client) {
+		if ttl <= 0 {
+			c.chainCache = nil
+			return
+		}
+		c.chainCache = newTTLCache(ttl, (*ChainListResponse).copy)
+	}
+}
+
+// GetSupportedChains lists the chains the gateway currently offers, with
+// their availability and amount limits, including chains newer than the
+// Chain* constants of this SDK. CreatePayment rejects such chains unless
+// WithChainTypeCheck(false) is set.
+//
+// With WithSupportedChainsCache the list is answered from the cache while
+// it has not expired, with Attempts set to 0.
+func (c *Client) GetSupportedChains(opts ...RequestOption) (*ChainListResponse, error) {
+	return c.getSupportedChains(context.Background(), opts...)
+}
+
+// getSupportedChains is GetSupportedChains bounded by ctx
+func (c *Client) getSupportedChains(ctx context.Context, opts ...RequestOption) (*ChainListResponse, error) {
+	if c.chainCache == nil {
+		return c.fetchSupportedChains(ctx, opts...)
+	}
+	apiKey := c.credentials().apiKey
+	resp, hit, err := c.chainCache.getOrFetch(apiKey, func() (*ChainListResponse, error) {
+		return c.getSupportedChainsFresh(ctx, apiKey, opts...)
+	})
+	if hit {
+		return cachedChains(resp), nil
+	}
+	return resp, err
+}
+
+// GetSupportedChainsFresh lists the supported chains from the API,
+// bypassing and then updating the cache set with WithSupportedChainsCache
+func (c *Client) GetSupportedChainsFresh(opts ...RequestOption) (*ChainListResponse, error) {
+	return c.getSupportedChainsFresh(context.Background(), c.credentials().apiKey, opts...)
+}
+
+// getSupportedChainsFresh fetches the chains for apiKey and caches them
+func (c *Client) getSupportedChainsFresh(ctx context.Context, apiKey string, opts ...RequestOption) (*ChainListResponse, error) {
+	resp, err := c.fetchSupportedChains(ctx, append([]RequestOption{withAPIKey(apiKey)}, opts...)...)
+	if err == nil && resp.Data != nil && c.chainCache != nil {
+		c.chainCache.put(apiKey, resp)
+	}
+	return resp, err
+}
+
+func (c *Client) fetchSupportedChains(ctx context.Context, opts ...RequestOption) (*ChainListResponse, error) {
+	var resp ChainListResponse
+	err := c.request(ctx, "GET", "/merchant/chains", nil, &resp, opts...)
+	return &resp, err
+}
+
+// cachedChains marks resp as answered without an HTTP attempt
+func cachedChains(resp *ChainListResponse) *ChainListResponse {
+	resp.Attempts = 0
+	resp.RateLimitWait = 0
+	return resp
+}
+
+// WithChainLimitCheck makes CreatePayment and BulkCreatePayments check the
+// amount of orders with a ChainType against the limits GetSupportedChains
+// reports, failing with a *ValidationError that names the limit instead of
+// an *APIError with ErrCodeAmountChannelUnavail. The list is fetched for
+// every call, so combine it with WithSupportedChainsCache. If it cannot be
+// fetched, or does not include the chain, the order is sent unchecked.
+func WithChainLimitCheck(enabled bool) Option {
+	return func(c *Client) {
+		c.checkChainLimits = enabled
+	}
+}
+
+// supportedChainsForCheck returns the chains for WithChainLimitCheck, or
+// nil when the check is off or the list cannot be fetched
+func (c *Client) supportedChainsForCheck(ctx context.Context) *ChainListResponse {
+	if !c.checkChainLimits {
+		return nil
+	}
+	chains, err := c.getSupportedChains(ctx)
+	if err != nil {
+		c.logger.Log(ctx, LevelWarn, "cryptomepay: sending order without chain limit check", "error", err)
+		return nil
+	}
+	return chains
+}
+
+// checkChainLimits checks the amount of prepared params against chains
+func checkChainLimits(chains *ChainListResponse, params *CreatePaymentParams) error {
+	if chains == nil || params.ChainType == "" {
+		return nil
+	}
+	info, ok := chains.Chain(params.ChainType)
+	if !ok {
+		return nil
+	}
+	amount := params.Amount
+	if params.AmountString != "" {
+		amount, _ = strconv.ParseFloat(params.AmountString, 64)
+	}
+	return info.CheckAmount(amount)
+}
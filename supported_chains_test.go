@@ -0,0 +1,197 @@
+package cryptomepay
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const chainsBody = `{"status_code":200,"data":[
+	{"chain_type":"TRC20","name":"TRON","available":true,"min_amount":1,"max_amount":10000},
+	{"chain_type":"BSC","available":false,"min_amount":5},
+	{"chain_type":"SOLANA","name":"Solana","available":true,"min_amount":1}
+]}`
+
+func TestGetSupportedChains(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Write([]byte(chainsBody))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+	resp, err := client.GetSupportedChains()
+	assert.NoError(t, err)
+	assert.Equal(t, "/merchant/chains", path)
+	assert.Len(t, resp.Data, 3)
+
+	trc20, ok := resp.Chain(ChainTRC20)
+	assert.True(t, ok)
+	assert.Equal(t, "TRON", trc20.DisplayName())
+	assert.True(t, trc20.Accepts(100))
+	assert.False(t, trc20.Accepts(0.5))
+	assert.False(t, trc20.Accepts(20000))
+
+	bsc, _ := resp.Chain(ChainBSC)
+	assert.Equal(t, "BNB Smart Chain", bsc.DisplayName())
+	assert.False(t, bsc.Accepts(100))
+
+	// Chains unknown to the SDK are listed too
+	solana, ok := resp.Chain("SOLANA")
+	assert.True(t, ok)
+	assert.False(t, solana.ChainType.Valid())
+	assert.True(t, solana.Accepts(1e6))
+
+	_, ok = resp.Chain(ChainETH)
+	assert.False(t, ok)
+}
+
+func TestSupportedChainsCache(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(chainsBody))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithSupportedChainsCache(50*time.Millisecond),
+	)
+
+	resp, err := client.GetSupportedChains()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, resp.Attempts)
+
+	// Changing a cached response must not affect the next caller
+	resp.Data[0].Available = false
+
+	resp, err = client.GetSupportedChains()
+	assert.NoError(t, err)
+	assert.True(t, resp.Data[0].Available)
+	assert.Equal(t, 0, resp.Attempts)
+	assert.Equal(t, int32(1), calls)
+
+	_, err = client.GetSupportedChainsFresh()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), calls)
+
+	time.Sleep(60 * time.Millisecond)
+	_, err = client.GetSupportedChains()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), calls)
+
+	// Without the option every call fetches
+	uncached := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+	uncached.GetSupportedChains()
+	uncached.GetSupportedChains()
+	assert.Equal(t, int32(5), calls)
+}
+
+func TestChainInfoCheckAmount(t *testing.T) {
+	info := ChainInfo{ChainType: ChainBSC, Available: true, MinAmount: 5, MaxAmount: 1000}
+	assert.NoError(t, info.CheckAmount(5))
+	assert.NoError(t, info.CheckAmount(1000))
+	assert.EqualError(t, info.CheckAmount(4.99), "cryptomepay: invalid amount: 4.99 is below the BSC minimum of 5.00")
+	assert.EqualError(t, info.CheckAmount(1000.01), "cryptomepay: invalid amount: 1000.01 is above the BSC maximum of 1000.00")
+
+	info.Available = false
+	var validationErr *ValidationError
+	if assert.ErrorAs(t, info.CheckAmount(10), &validationErr) {
+		assert.Equal(t, "chain_type", validationErr.Field)
+	}
+}
+
+func TestWithChainLimitCheck(t *testing.T) {
+	var chainCalls, orderCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/merchant/chains" {
+			atomic.AddInt32(&chainCalls, 1)
+			w.Write([]byte(chainsBody))
+			return
+		}
+		atomic.AddInt32(&orderCalls, 1)
+		w.Write([]byte(`{"status_code":200,"data":{"trade_id":"CP1"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithChainLimitCheck(true),
+		WithSupportedChainsCache(time.Minute),
+	)
+	params := func(chain ChainType, amount string) *CreatePaymentParams {
+		return &CreatePaymentParams{OrderID: "ORDER_001", AmountString: amount, ChainType: chain, NotifyURL: "https://example.com/webhook"}
+	}
+
+	_, err := client.CreatePayment(params(ChainTRC20, "0.50"))
+	var validationErr *ValidationError
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, "amount", validationErr.Field)
+		assert.Contains(t, err.Error(), "TRC20 minimum of 1.00")
+	}
+	_, err = client.CreatePayment(params(ChainBSC, "10"))
+	assert.ErrorContains(t, err, "BSC is not available")
+	assert.Equal(t, int32(0), orderCalls)
+
+	// Chains the gateway did not list, and orders without a chain, are sent
+	_, err = client.CreatePayment(params(ChainTRC20, "10"))
+	assert.NoError(t, err)
+	_, err = client.CreatePayment(params(ChainETH, "0.01"))
+	assert.NoError(t, err)
+	_, err = client.CreatePayment(params("", "0.01"))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), orderCalls)
+	assert.Equal(t, int32(1), chainCalls)
+
+	_, err = client.BulkCreatePayments(context.Background(), []*CreatePaymentParams{params(ChainTRC20, "10"), params(ChainTRC20, "20000")})
+	assert.ErrorContains(t, err, "order 1: cryptomepay: invalid amount: 20000.00 is above the TRC20 maximum of 10000.00")
+	assert.Equal(t, int32(3), orderCalls)
+}
+
+func TestWithChainLimitCheckUnavailableList(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/merchant/chains" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"status_code":200,"data":{"trade_id":"CP1"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL), WithChainLimitCheck(true))
+	resp, err := client.CreatePayment(&CreatePaymentParams{OrderID: "ORDER_001", Amount: 0.01, ChainType: ChainTRC20, NotifyURL: "https://example.com/webhook"})
+	assert.NoError(t, err)
+	assert.Equal(t, "CP1", resp.Data.TradeID)
+}
+
+func TestWithChainLimitCheckUsesContext(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The chain list hangs until the caller gives up
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL), WithChainLimitCheck(true))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.BulkCreatePayments(ctx, []*CreatePaymentParams{
+		{OrderID: "O1", Amount: 1, ChainType: ChainTRC20, NotifyURL: "https://example.com/webhook"},
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second, "the chain list fetch honours ctx")
+}
@@ -0,0 +1,89 @@
+package cryptomepay
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimestampLayout is the layout of the gateway's timestamps, such as
+// OrderData.CreatedAt and PaidAt. They carry no zone; use
+// time.ParseInLocation with this layout to read them as other than UTC.
+const TimestampLayout = "2006-01-02 15:04:05"
+
+// ParseTimestamp parses a gateway timestamp in TimestampLayout as UTC.
+// RFC 3339 timestamps, with their own offset, are accepted as well.
+func ParseTimestamp(s string) (time.Time, error) {
+	if t, err := time.ParseInLocation(TimestampLayout, s, time.UTC); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("cryptomepay: invalid timestamp %q", s)
+}
+
+// CreatedAtTime returns CreatedAt parsed with ParseTimestamp
+func (o *OrderData) CreatedAtTime() (time.Time, error) {
+	return ParseTimestamp(o.CreatedAt)
+}
+
+// PaidAtTime returns PaidAt parsed with ParseTimestamp. For an order that
+// is not paid, PaidAt is empty and the zero time is returned with
+// ErrOrderNotPaid.
+func (o *OrderData) PaidAtTime() (time.Time, error) {
+	if o.PaidAt == "" {
+		return time.Time{}, ErrOrderNotPaid
+	}
+	return ParseTimestamp(o.PaidAt)
+}
+
+// maxUnixSeconds is the largest expiration epoch read as seconds, in the
+// year 5138; larger values are read as milliseconds
+const maxUnixSeconds = 100_000_000_000
+
+// expirationTime converts an expiration epoch, the zero time for 0
+func expirationTime(epoch int64) time.Time {
+	switch {
+	case epoch <= 0:
+		return time.Time{}
+	case epoch > maxUnixSeconds:
+		return time.UnixMilli(epoch)
+	default:
+		return time.Unix(epoch, 0)
+	}
+}
+
+// ExpiresAt returns ExpirationTime, a unix time in seconds, as a time.Time.
+// A value too large for seconds is read as milliseconds. It is the zero
+// time when the gateway does not report one.
+func (p *PaymentData) ExpiresAt() time.Time {
+	return expirationTime(p.ExpirationTime)
+}
+
+// IsExpired reports whether the payment window has closed. It is false
+// when no ExpirationTime is reported.
+func (p *PaymentData) IsExpired() bool {
+	expiresAt := p.ExpiresAt()
+	return !expiresAt.IsZero() && !time.Now().Before(expiresAt)
+}
+
+// ExpiresAt returns ExpirationTime, a unix time in seconds, as a time.Time.
+// A value too large for seconds is read as milliseconds. It is the zero
+// time when the gateway does not report one.
+func (o *OrderData) ExpiresAt() time.Time {
+	return expirationTime(o.ExpirationTime)
+}
+
+// IsExpired reports whether the order has expired: its status is
+// StatusExpired, or it is still pending past its ExpirationTime. Paid
+// orders are never expired.
+func (o *OrderData) IsExpired() bool {
+	switch o.Status {
+	case StatusExpired:
+		return true
+	case StatusPaid:
+		return false
+	}
+	expiresAt := o.ExpiresAt()
+	return !expiresAt.IsZero() && !time.Now().Before(expiresAt)
+}
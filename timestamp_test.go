@@ -0,0 +1,79 @@
+package cryptomepay
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	got, err := ParseTimestamp("2025-12-01 10:02:03")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 12, 1, 10, 2, 3, 0, time.UTC), got)
+
+	got, err = ParseTimestamp("2025-12-01T10:02:03+08:00")
+	assert.NoError(t, err)
+	assert.True(t, got.Equal(time.Date(2025, 12, 1, 2, 2, 3, 0, time.UTC)))
+
+	_, err = ParseTimestamp("01/12/2025")
+	assert.ErrorContains(t, err, "invalid timestamp")
+}
+
+func TestOrderDataTimes(t *testing.T) {
+	var order OrderData
+	assert.NoError(t, json.Unmarshal([]byte(`{"created_at":"2025-12-01 10:00:00","paid_at":"2025-12-01 10:04:30"}`), &order))
+
+	created, err := order.CreatedAtTime()
+	assert.NoError(t, err)
+	paid, err := order.PaidAtTime()
+	assert.NoError(t, err)
+	assert.Equal(t, 4*time.Minute+30*time.Second, paid.Sub(created))
+
+	// Unpaid orders have no paid_at
+	order.PaidAt = ""
+	paid, err = order.PaidAtTime()
+	assert.ErrorIs(t, err, ErrOrderNotPaid)
+	assert.True(t, paid.IsZero())
+
+	order.CreatedAt = ""
+	_, err = order.CreatedAtTime()
+	assert.Error(t, err)
+}
+
+func TestExpiresAt(t *testing.T) {
+	// The gateway reports seconds; milliseconds are recognized too
+	want := time.Date(2025, 12, 1, 10, 0, 0, 0, time.UTC)
+	seconds := &PaymentData{ExpirationTime: want.Unix()}
+	assert.True(t, seconds.ExpiresAt().Equal(want))
+	millis := &PaymentData{ExpirationTime: want.UnixMilli()}
+	assert.True(t, millis.ExpiresAt().Equal(want))
+
+	assert.True(t, (&PaymentData{}).ExpiresAt().IsZero())
+	assert.True(t, (&OrderData{ExpirationTime: want.Unix()}).ExpiresAt().Equal(want))
+}
+
+func TestIsExpired(t *testing.T) {
+	past := time.Now().Add(-time.Minute).Unix()
+	future := time.Now().Add(time.Hour).Unix()
+
+	assert.True(t, (&PaymentData{ExpirationTime: past}).IsExpired())
+	assert.False(t, (&PaymentData{ExpirationTime: future}).IsExpired())
+	assert.False(t, (&PaymentData{ExpirationTime: time.Now().Add(time.Hour).UnixMilli()}).IsExpired())
+	assert.False(t, (&PaymentData{}).IsExpired())
+
+	tests := []struct {
+		order OrderData
+		want  bool
+	}{
+		{OrderData{Status: StatusPending, ExpirationTime: past}, true},
+		{OrderData{Status: StatusPending, ExpirationTime: future}, false},
+		{OrderData{Status: StatusPending}, false},
+		{OrderData{Status: StatusExpired, ExpirationTime: future}, true},
+		{OrderData{Status: StatusPaid, ExpirationTime: past}, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.order.IsExpired(), "%+v", tt.order)
+	}
+}
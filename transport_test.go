@@ -0,0 +1,201 @@
+package cryptomepay
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithProxy(t *testing.T) {
+	var proxied []string
+	var proxyAuth string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		proxyAuth = r.Header.Get("Proxy-Authorization")
+		w.Write([]byte(`{"status_code":200,"data":{"name":"Shop"}}`))
+	}))
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	proxyURL.User = url.UserPassword("alice", "s3cret")
+
+	// The gateway host does not resolve, so the request must go via the proxy
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL("http://gateway.invalid"),
+		WithProxy(proxyURL.String()),
+		WithTimeout(5*time.Second),
+	)
+
+	_, err := client.GetMerchantInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://gateway.invalid/merchant/info"}, proxied)
+	assert.Equal(t, "Basic "+base64.StdEncoding.EncodeToString([]byte("alice:s3cret")), proxyAuth)
+	assert.Equal(t, 5*time.Second, client.httpClient.Timeout)
+}
+
+func TestWithProxyComposesWithTransport(t *testing.T) {
+	transport := &http.Transport{MaxIdleConnsPerHost: 64}
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithProxy("socks5://proxy.internal:1080"),
+		WithTransport(transport),
+	)
+
+	configured := client.httpClient.Transport.(*http.Transport)
+	assert.NotSame(t, transport, configured)
+	assert.Equal(t, 64, configured.MaxIdleConnsPerHost)
+	assert.Nil(t, transport.Proxy, "the caller's transport is not modified")
+
+	req, _ := http.NewRequest("GET", "https://api.example.com", nil)
+	proxyURL, err := configured.Proxy(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "socks5://proxy.internal:1080", proxyURL.String())
+
+	// The default transport is copied too
+	NewClientWithOptions("sk_test_key", "test_secret", WithProxy("http://proxy.internal:3128"))
+	proxyURL, _ = http.DefaultTransport.(*http.Transport).Proxy(req)
+	assert.Nil(t, proxyURL)
+}
+
+func TestWithProxyErrors(t *testing.T) {
+	for _, proxyURL := range []string{"", "proxy.internal:3128", "ftp://proxy.internal"} {
+		client := NewClientWithOptions("sk_test_key", "test_secret", WithProxy(proxyURL))
+		_, err := client.GetMerchantInfo()
+		var validationErr *ValidationError
+		if assert.ErrorAs(t, err, &validationErr, proxyURL) {
+			assert.Equal(t, "proxy_url", validationErr.Field)
+		}
+	}
+
+	// A transport that cannot be configured fails every request
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithPlayback("testdata/none.json"),
+		WithProxy("http://proxy.internal:3128"),
+	)
+	_, err := client.GetMerchantInfo()
+	assert.ErrorContains(t, err, "need an *http.Transport")
+}
+
+// clientCertificate returns a self-signed client certificate and a pool
+// that trusts it
+func clientCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "merchant"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	leaf, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, pool
+}
+
+func TestWithClientCertificate(t *testing.T) {
+	cert, clientCAs := clientCertificate(t)
+
+	var presented string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		presented = r.TLS.PeerCertificates[0].Subject.CommonName
+		w.Write([]byte(`{"status_code":200,"data":{"name":"Shop"}}`))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(server.Certificate())
+
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithTLSConfig(&tls.Config{RootCAs: rootCAs}),
+		WithClientCertificate(cert),
+		WithTransport(&http.Transport{MaxIdleConnsPerHost: 16}),
+	)
+	_, err := client.GetMerchantInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "merchant", presented)
+
+	// Without the certificate the handshake is refused
+	withoutCert := NewClientWithOptions("sk_test_key", "test_secret",
+		WithBaseURL(server.URL),
+		WithTLSConfig(&tls.Config{RootCAs: rootCAs}),
+		WithRetry(1, 0),
+	)
+	_, err = withoutCert.GetMerchantInfo()
+	assert.Error(t, err)
+}
+
+func TestWithPinnedCertificates(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status_code":200,"data":{"name":"Shop"}}`))
+	}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(server.Certificate())
+	newClient := func(opts ...Option) *Client {
+		return NewClientWithOptions("sk_test_key", "test_secret", append([]Option{
+			WithBaseURL(server.URL),
+			WithTLSConfig(&tls.Config{RootCAs: rootCAs}),
+			WithRetry(1, 0),
+		}, opts...)...)
+	}
+
+	other, _ := clientCertificate(t)
+	pinned := newClient(WithPinnedCertificates([][]byte{PublicKeyPin(other.Leaf), PublicKeyPin(server.Certificate())}))
+	_, err := pinned.GetMerchantInfo()
+	assert.NoError(t, err)
+
+	unpinned := newClient(WithPinnedCertificates([][]byte{PublicKeyPin(other.Leaf)}))
+	_, err = unpinned.GetMerchantInfo()
+	assert.ErrorIs(t, err, ErrCertificateNotPinned)
+}
+
+func TestWithMinTLSVersion(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status_code":200,"data":{"name":"Shop"}}`))
+	}))
+	server.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(server.Certificate())
+
+	for version, ok := range map[uint16]bool{tls.VersionTLS12: true, tls.VersionTLS13: false} {
+		client := NewClientWithOptions("sk_test_key", "test_secret",
+			WithBaseURL(server.URL),
+			WithTLSConfig(&tls.Config{RootCAs: rootCAs}),
+			WithMinTLSVersion(version),
+			WithRetry(1, 0),
+		)
+		assert.Equal(t, version, client.httpClient.Transport.(*http.Transport).TLSClientConfig.MinVersion)
+		_, err := client.GetMerchantInfo()
+		assert.Equal(t, ok, err == nil, "%x: %v", version, err)
+	}
+}
@@ -0,0 +1,77 @@
+package cryptomepay
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// usdtToken describes the mainnet USDT contract of a chain
+type usdtToken struct {
+	contract string
+	decimals int
+
+	// chainID is the EIP-155 chain id, 0 for TRON
+	chainID int
+}
+
+var usdtTokens = map[ChainType]usdtToken{
+	ChainETH:      {contract: "0xdAC17F958D2ee523a2206206994597C13D831ec7", decimals: 6, chainID: 1},
+	ChainBSC:      {contract: "0x55d398326f99059fF775485246999027B3197955", decimals: 18, chainID: 56},
+	ChainPolygon:  {contract: "0xc2132D05D31c914a87C6611C10748AEb04B58e8F", decimals: 6, chainID: 137},
+	ChainArbitrum: {contract: "0xFd086bC7CD5C481DCC9C85ebE478A1C0b69FCbb9", decimals: 6, chainID: 42161},
+	ChainTRC20:    {contract: "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", decimals: 6},
+}
+
+// PaymentURI returns a wallet deep link that pays ActualAmount of USDT to
+// the payment address (Token), suitable for a QR code on a checkout page.
+//
+// EVM chains get an EIP-681 token transfer such as
+// "ethereum:0xdAC1…1ec7@1/transfer?address=0x…&uint256=15625000", with the
+// amount in the token's base units. TRON has no URI standard; for
+// ChainTRC20 the link is "tron:T…?amount=15.625&token=TR7N…Lj6t", which not
+// every wallet understands. The links name the mainnet USDT contracts, so
+// they do not suit sandbox orders on a test network.
+//
+// An invalid address is reported as an *AddressError, and an unknown chain
+// or a non-positive amount as a *ValidationError.
+func (d *PaymentData) PaymentURI() (string, error) {
+	token, ok := usdtTokens[d.ChainType]
+	if !ok {
+		return "", &ValidationError{Field: "chain_type", Message: fmt.Sprintf("no payment URI for chain %q", d.ChainType)}
+	}
+	if roundActualAmount(d.ActualAmount) <= 0 {
+		return "", &ValidationError{Field: "actual_amount", Message: "must be greater than 0"}
+	}
+	if err := ValidateWalletAddress(string(d.ChainType), d.Token); err != nil {
+		return "", err
+	}
+
+	amount := formatActualAmount(d.ActualAmount)
+	if d.ChainType == ChainTRC20 {
+		query := url.Values{"amount": {trimDecimal(amount)}, "token": {token.contract}}
+		return "tron:" + d.Token + "?" + query.Encode(), nil
+	}
+	return fmt.Sprintf("ethereum:%s@%d/transfer?address=%s&uint256=%s",
+		token.contract, token.chainID, d.Token, baseUnits(amount, token.decimals)), nil
+}
+
+// baseUnits converts a decimal string with ActualAmountDecimals fractional
+// digits into an integer count of units with decimals digits, exactly
+func baseUnits(amount string, decimals int) string {
+	whole, frac, _ := strings.Cut(amount, ".")
+	frac += strings.Repeat("0", decimals)
+	units := strings.TrimLeft(whole+frac[:decimals], "0")
+	if units == "" {
+		return "0"
+	}
+	return units
+}
+
+// trimDecimal drops trailing fractional zeros, "15.6250" becoming "15.625"
+func trimDecimal(amount string) string {
+	if !strings.Contains(amount, ".") {
+		return amount
+	}
+	return strings.TrimSuffix(strings.TrimRight(amount, "0"), ".")
+}
@@ -0,0 +1,49 @@
+package cryptomepay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentURI(t *testing.T) {
+	tests := []struct {
+		payment PaymentData
+		want    string
+	}{
+		{
+			PaymentData{ChainType: ChainETH, Token: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", ActualAmount: 15.625},
+			"ethereum:0xdAC17F958D2ee523a2206206994597C13D831ec7@1/transfer?address=0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed&uint256=15625000",
+		},
+		{
+			PaymentData{ChainType: ChainBSC, Token: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", ActualAmount: 0.1},
+			"ethereum:0x55d398326f99059fF775485246999027B3197955@56/transfer?address=0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed&uint256=100000000000000000",
+		},
+		{
+			PaymentData{ChainType: ChainArbitrum, Token: "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359", ActualAmount: 14.5001},
+			"ethereum:0xFd086bC7CD5C481DCC9C85ebE478A1C0b69FCbb9@42161/transfer?address=0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359&uint256=14500100",
+		},
+		{
+			PaymentData{ChainType: ChainTRC20, Token: "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", ActualAmount: 20},
+			"tron:TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t?amount=20&token=TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t",
+		},
+	}
+	for _, tt := range tests {
+		uri, err := tt.payment.PaymentURI()
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, uri)
+	}
+}
+
+func TestPaymentURIErrors(t *testing.T) {
+	var validationErr *ValidationError
+	_, err := (&PaymentData{ChainType: "SOLANA", Token: "addr", ActualAmount: 1}).PaymentURI()
+	assert.ErrorAs(t, err, &validationErr)
+
+	_, err = (&PaymentData{ChainType: ChainETH, Token: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", ActualAmount: 0.00001}).PaymentURI()
+	assert.ErrorAs(t, err, &validationErr)
+
+	_, err = (&PaymentData{ChainType: ChainTRC20, Token: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", ActualAmount: 1}).PaymentURI()
+	var addrErr *AddressError
+	assert.ErrorAs(t, err, &addrErr)
+}
@@ -0,0 +1,57 @@
+package cryptomepay
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrPaymentExpired is returned by WaitForPayment when the order expires
+// before it is paid
+var ErrPaymentExpired = errors.New("cryptomepay: payment expired")
+
+// WaitForPayment polls the order with trade_id tradeID every pollInterval
+// until it is paid or expired, and returns the final order.
+//
+// An expired order is returned together with ErrPaymentExpired. When the
+// order reports an ExpirationTime, polling stops on its own: the last query
+// is made at the expiration time and, if the order is still pending then,
+// ErrPaymentExpired is returned without polling further.
+func (c *Client) WaitForPayment(ctx context.Context, tradeID string, pollInterval time.Duration, opts ...RequestOption) (*OrderData, error) {
+	if pollInterval <= 0 {
+		return nil, &ValidationError{Field: "poll_interval", Message: "must be positive"}
+	}
+
+	for {
+		resp, err := c.queryOrder(ctx, "trade_id", tradeID, opts...)
+		if err == nil && resp.StatusCode != 200 {
+			err = NewAPIError(resp.StatusCode, resp.Message, resp.RequestID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		order := resp.Data
+
+		if order.Status == StatusPaid {
+			return order, nil
+		}
+		if order.IsExpired() {
+			return order, ErrPaymentExpired
+		}
+
+		wait := pollInterval
+		if expiresAt := order.ExpiresAt(); !expiresAt.IsZero() {
+			if untilExpiry := time.Until(expiresAt); untilExpiry < wait {
+				wait = untilExpiry
+			}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return order, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package cryptomepay
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// statusServer reports StatusPending until finalAfter queries, then final
+func statusServer(finalAfter int32, final PaymentStatus, expiration int64, calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := StatusPending
+		if atomic.AddInt32(calls, 1) > finalAfter {
+			status = final
+		}
+		json.NewEncoder(w).Encode(OrderResponse{
+			StatusCode: 200,
+			Data:       &OrderData{TradeID: "CP1", Status: status, ExpirationTime: expiration},
+		})
+	}))
+}
+
+func TestWaitForPaymentPaid(t *testing.T) {
+	var calls int32
+	server := statusServer(2, StatusPaid, 0, &calls)
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	order, err := client.WaitForPayment(context.Background(), "CP1", time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusPaid, order.Status)
+	assert.Equal(t, int32(3), calls)
+}
+
+func TestWaitForPaymentExpiredStatus(t *testing.T) {
+	var calls int32
+	server := statusServer(1, StatusExpired, 0, &calls)
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	order, err := client.WaitForPayment(context.Background(), "CP1", time.Millisecond)
+	assert.ErrorIs(t, err, ErrPaymentExpired)
+	assert.Equal(t, StatusExpired, order.Status)
+}
+
+func TestWaitForPaymentStopsAtExpirationTime(t *testing.T) {
+	var calls int32
+	// The gateway never marks the order expired, but its expiration time passes
+	server := statusServer(1000, StatusPending, time.Now().Add(time.Second).Unix(), &calls)
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	start := time.Now()
+	_, err := client.WaitForPayment(context.Background(), "CP1", time.Hour)
+	assert.ErrorIs(t, err, ErrPaymentExpired)
+	assert.Less(t, time.Since(start), 3*time.Second)
+	assert.LessOrEqual(t, calls, int32(2))
+}
+
+func TestWaitForPaymentCanceled(t *testing.T) {
+	var calls int32
+	server := statusServer(1000, StatusPending, 0, &calls)
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.WaitForPayment(ctx, "CP1", 10*time.Millisecond)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,42 @@
+package cryptomepay
+
+import "context"
+
+// WalletData is a receiving wallet configured for the merchant
+type WalletData struct {
+	ChainType ChainType `json:"chain_type"`
+	Address   string    `json:"address"`
+
+	// Available is false while the wallet cannot take new orders, for
+	// example when all its addresses are held by pending orders
+	Available bool `json:"available"`
+}
+
+// WalletListResponse is the API response for ListWallets
+type WalletListResponse struct {
+	StatusCode int          `json:"status_code"`
+	Message    string       `json:"message"`
+	Data       []WalletData `json:"data"`
+	RequestID  string       `json:"request_id"`
+
+	ResponseMeta
+}
+
+// ListWallets lists the merchant's receiving wallets per chain. Checking it
+// at startup catches a missing chain before CreatePayment fails with
+// ErrCodeNoAvailableWallet.
+func (c *Client) ListWallets(opts ...RequestOption) (*WalletListResponse, error) {
+	var resp WalletListResponse
+	err := c.request(context.Background(), "GET", "/merchant/wallets", nil, &resp, opts...)
+	return &resp, err
+}
+
+// Available reports whether an available wallet is configured for chain
+func (r *WalletListResponse) Available(chain ChainType) bool {
+	for _, w := range r.Data {
+		if w.ChainType == chain && w.Available {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package cryptomepay
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListWallets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/merchant/wallets", r.URL.Path)
+		w.Write([]byte(`{"status_code":200,"data":[
+			{"chain_type":"BSC","address":"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed","available":true},
+			{"chain_type":"TRC20","address":"TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t","available":false}
+		]}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+	resp, err := client.ListWallets()
+	assert.NoError(t, err)
+	assert.Len(t, resp.Data, 2)
+	assert.Equal(t, ChainType(ChainBSC), resp.Data[0].ChainType)
+	assert.Equal(t, "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", resp.Data[1].Address)
+
+	assert.True(t, resp.Available(ChainBSC))
+	assert.False(t, resp.Available(ChainTRC20))
+	assert.False(t, resp.Available(ChainETH))
+}
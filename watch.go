@@ -0,0 +1,135 @@
+package cryptomepay
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// WatchClockSkew is how far before the checkpoint each WatchNewPaidOrders
+// poll looks, so orders stamped slightly out of order by the gateway, or by
+// a clock that disagrees with ours, are not missed at the boundary.
+const WatchClockSkew = 5 * time.Minute
+
+// WatchNewPaidOrders polls ListOrders every interval for paid orders newer
+// than checkpoint and calls fn once per order, oldest first. It is a pull
+// based alternative to webhooks.
+//
+// Orders are deduplicated by trade_id across polls, including those seen
+// again inside the WatchClockSkew overlap. PaidAt is parsed with
+// ParseTimestamp as UTC, whatever the location of checkpoint. An order
+// without a valid PaidAt is delivered once and then ignored for as long as
+// the polls keep returning it. WatchNewPaidOrders runs until ctx is done or
+// a poll fails, and returns the checkpoint reached, in the location of
+// checkpoint, so a caller can resume from it.
+//
+// The gateway has no filter on the paid time, so each poll lists paid
+// orders by their list date from the UTC day of the checkpoint, less
+// WatchClockSkew. An order created before that day and paid after the
+// checkpoint is not returned and not delivered; reconcile such orders with
+// ListOrdersAll or webhooks.
+func (c *Client) WatchNewPaidOrders(ctx context.Context, checkpoint time.Time, interval time.Duration, fn func(OrderData), opts ...RequestOption) (time.Time, error) {
+	if interval <= 0 {
+		return checkpoint, &ValidationError{Field: "interval", Message: "must be positive"}
+	}
+
+	seen := make(map[string]time.Time)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		var err error
+		if checkpoint, err = c.pollPaidOrders(ctx, checkpoint, seen, fn, opts...); err != nil {
+			return checkpoint, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return checkpoint, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// pollPaidOrders runs one watch poll and returns the advanced checkpoint
+func (c *Client) pollPaidOrders(ctx context.Context, checkpoint time.Time, seen map[string]time.Time, fn func(OrderData), opts ...RequestOption) (time.Time, error) {
+	since := checkpoint.Add(-WatchClockSkew)
+
+	type paidOrder struct {
+		order  OrderData
+		paidAt time.Time
+	}
+	var fresh []paidOrder
+
+	// returned holds the orders of this poll, to forget undated ones once
+	// the list no longer includes them
+	returned := make(map[string]bool)
+
+	params := &ListOrdersParams{
+		PageSize:  c.maxPageSize,
+		Status:    StatusPaid,
+		StartTime: since,
+	}
+
+	for page, fetched := 1, 0; ; page++ {
+		params.Page = page
+		resp, err := c.listOrders(ctx, params, opts...)
+		if err != nil {
+			return checkpoint, err
+		}
+		if resp.StatusCode != 200 {
+			return checkpoint, &APIError{StatusCode: resp.StatusCode, Message: resp.Message, RequestID: resp.RequestID}
+		}
+		if resp.Data == nil {
+			break
+		}
+
+		for _, order := range resp.Data.List {
+			returned[order.TradeID] = true
+			if _, ok := seen[order.TradeID]; ok {
+				continue
+			}
+
+			// An unparseable PaidAt cannot be placed before the
+			// checkpoint, so it is delivered, sorted last, and kept in
+			// seen with a zero time while the list returns it
+			paidAt, err := order.PaidAtTime()
+			if err != nil {
+				paidAt = time.Time{}
+			} else if paidAt.Before(since) {
+				continue
+			}
+			fresh = append(fresh, paidOrder{order, paidAt})
+		}
+
+		fetched += len(resp.Data.List)
+		if len(resp.Data.List) < params.PageSize || fetched >= resp.Data.Total {
+			break
+		}
+	}
+
+	sort.SliceStable(fresh, func(i, j int) bool {
+		if fresh[i].paidAt.IsZero() || fresh[j].paidAt.IsZero() {
+			return !fresh[i].paidAt.IsZero()
+		}
+		return fresh[i].paidAt.Before(fresh[j].paidAt)
+	})
+
+	for _, p := range fresh {
+		fn(p.order)
+		seen[p.order.TradeID] = p.paidAt
+		if p.paidAt.After(checkpoint) {
+			checkpoint = p.paidAt.In(checkpoint.Location())
+		}
+	}
+
+	// Dated orders older than the overlap window are skipped by their
+	// PaidAt, and undated ones are forgotten once they are not listed
+	for tradeID, paidAt := range seen {
+		if paidAt.IsZero() && !returned[tradeID] || !paidAt.IsZero() && paidAt.Before(checkpoint.Add(-WatchClockSkew)) {
+			delete(seen, tradeID)
+		}
+	}
+
+	return checkpoint, nil
+}
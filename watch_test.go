@@ -0,0 +1,203 @@
+package cryptomepay
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// watchServer answers each list request with the next of polls, repeating
+// the last one, and checks the paid status and start_date filters
+func watchServer(t *testing.T, polls [][]OrderData, startDate string) *httptest.Server {
+	var mu sync.Mutex
+	call := 0
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "2", r.URL.Query().Get("status"))
+		assert.Equal(t, startDate, r.URL.Query().Get("start_date"))
+
+		mu.Lock()
+		list := polls[len(polls)-1]
+		if call < len(polls) {
+			list = polls[call]
+		}
+		call++
+		mu.Unlock()
+
+		json.NewEncoder(w).Encode(OrderListResponse{
+			StatusCode: 200,
+			Data:       &OrderListData{List: list, Total: len(list)},
+		})
+	}))
+}
+
+func TestWatchNewPaidOrders(t *testing.T) {
+	checkpoint := time.Date(2025, 12, 1, 10, 0, 0, 0, time.UTC)
+
+	polls := [][]OrderData{
+		{
+			{TradeID: "CP2", PaidAt: "2025-12-01 10:02:00"},
+			{TradeID: "CP1", PaidAt: "2025-12-01 10:01:00"},
+			{TradeID: "OLD", PaidAt: "2025-12-01 09:00:00"},
+		},
+		// CP2 comes back inside the overlap; CP3 was stamped just before
+		// the new checkpoint by a skewed clock
+		{
+			{TradeID: "CP2", PaidAt: "2025-12-01 10:02:00"},
+			{TradeID: "CP3", PaidAt: "2025-12-01 10:01:30"},
+		},
+	}
+
+	server := watchServer(t, polls, "2025-12-01")
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var got []string
+	last, err := client.WatchNewPaidOrders(ctx, checkpoint, 5*time.Millisecond, func(order OrderData) {
+		got = append(got, order.TradeID)
+		if len(got) == 3 {
+			cancel()
+		}
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []string{"CP1", "CP2", "CP3"}, got)
+	assert.Equal(t, time.Date(2025, 12, 1, 10, 2, 0, 0, time.UTC), last)
+}
+
+func TestWatchNewPaidOrdersError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"status_code":500,"message":"internal error"}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+	checkpoint := time.Now()
+
+	last, err := client.WatchNewPaidOrders(context.Background(), checkpoint, time.Millisecond, func(OrderData) {})
+	var apiErr *APIError
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, checkpoint, last)
+
+	_, err = client.WatchNewPaidOrders(context.Background(), checkpoint, 0, func(OrderData) {})
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+}
+
+func TestWatchNewPaidOrdersLocalCheckpoint(t *testing.T) {
+	// 18:00 in UTC+8 is 10:00 UTC; PaidAt is always UTC
+	cst := time.FixedZone("CST", 8*3600)
+	checkpoint := time.Date(2025, 12, 1, 18, 0, 0, 0, cst)
+
+	server := watchServer(t, [][]OrderData{{
+		{TradeID: "CP1", PaidAt: "2025-12-01 10:01:00"},
+		{TradeID: "OLD", PaidAt: "2025-12-01 09:50:00"},
+	}}, "2025-12-01")
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var got []string
+	last, err := client.WatchNewPaidOrders(ctx, checkpoint, 5*time.Millisecond, func(order OrderData) {
+		got = append(got, order.TradeID)
+		cancel()
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []string{"CP1"}, got)
+	assert.Equal(t, time.Date(2025, 12, 1, 18, 1, 0, 0, cst), last)
+	assert.Equal(t, cst, last.Location())
+}
+
+func TestWatchNewPaidOrdersUndated(t *testing.T) {
+	checkpoint := time.Date(2025, 12, 1, 10, 0, 0, 0, time.UTC)
+
+	// BAD has no valid PaidAt and stays listed while the checkpoint moves
+	// well past the overlap window
+	polls := [][]OrderData{
+		{
+			{TradeID: "BAD", PaidAt: "not a time"},
+			{TradeID: "CP1", PaidAt: "2025-12-01 10:01:00"},
+		},
+		{
+			{TradeID: "BAD", PaidAt: "not a time"},
+			{TradeID: "CP2", PaidAt: "2025-12-01 10:10:00"},
+		},
+		{
+			{TradeID: "BAD", PaidAt: "not a time"},
+			{TradeID: "CP3", PaidAt: "2025-12-01 10:11:00"},
+		},
+	}
+	server := watchServer(t, polls, "2025-12-01")
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var got []string
+	last, err := client.WatchNewPaidOrders(ctx, checkpoint, 5*time.Millisecond, func(order OrderData) {
+		got = append(got, order.TradeID)
+		if order.TradeID == "CP3" {
+			cancel()
+		}
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []string{"CP1", "BAD", "CP2", "CP3"}, got)
+	assert.Equal(t, time.Date(2025, 12, 1, 10, 11, 0, 0, time.UTC), last)
+}
+
+func TestWatchNewPaidOrdersCreatedBeforeWindow(t *testing.T) {
+	checkpoint := time.Date(2025, 12, 1, 10, 0, 0, 0, time.UTC)
+
+	// The gateway filters start_date on the list date, so LATE, created the
+	// day before the window and paid after the checkpoint, is never listed
+	orders := []OrderData{
+		{TradeID: "LATE", CreatedAt: "2025-11-30 23:50:00", PaidAt: "2025-12-01 10:02:00"},
+		{TradeID: "CP1", CreatedAt: "2025-12-01 09:55:00", PaidAt: "2025-12-01 10:01:00"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := r.URL.Query().Get("start_date")
+		assert.Equal(t, "2025-12-01", start)
+
+		var list []OrderData
+		for _, order := range orders {
+			if order.CreatedAt[:len("2006-01-02")] >= start {
+				list = append(list, order)
+			}
+		}
+		json.NewEncoder(w).Encode(OrderListResponse{
+			StatusCode: 200,
+			Data:       &OrderListData{List: list, Total: len(list)},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var got []string
+	_, err := client.WatchNewPaidOrders(ctx, checkpoint, 5*time.Millisecond, func(order OrderData) {
+		got = append(got, order.TradeID)
+	})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, []string{"CP1"}, got)
+}
@@ -0,0 +1,367 @@
+package cryptomepay
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// maxWebhookBodySize bounds how much of a webhook body is read
+const maxWebhookBodySize = 1 << 20
+
+// WebhookSignatureHeader carries a signature over the raw webhook body, for
+// deliveries signed that way instead of with a signature field
+const WebhookSignatureHeader = "X-Signature"
+
+var (
+	// ErrEmptyWebhookBody is returned by ParseWebhook for a request without a body
+	ErrEmptyWebhookBody = errors.New("cryptomepay: empty webhook body")
+
+	// ErrMalformedWebhook is returned by ParseWebhook when the body cannot be decoded
+	ErrMalformedWebhook = errors.New("cryptomepay: malformed webhook body")
+)
+
+// WithWebhookVerifiedHook calls fn after every webhook signature check made
+// by ParseWebhook, VerifyWebhookSignature and the helpers built on them,
+// with the payload and whether it verified, for example to keep an audit
+// log of accepted and rejected deliveries. ParseWebhook calls fn once per
+// delivery whose body could be decoded. fn runs on the verifying goroutine,
+// so it is called concurrently from HTTP handlers and must be safe for
+// concurrent use; it must not modify payload. A nil fn removes the hook.
+func WithWebhookVerifiedHook(fn func(payload *WebhookPayload, ok bool)) Option {
+	return func(c *Client) {
+		c.webhookVerifiedHook = fn
+	}
+}
+
+// webhookVerified reports a signature check to the hook, if any
+func (c *Client) webhookVerified(payload *WebhookPayload, ok bool) {
+	if c.webhookVerifiedHook != nil {
+		c.webhookVerifiedHook(payload, ok)
+	}
+}
+
+// ParseWebhook reads and verifies a webhook delivered to an HTTP handler.
+//
+// The gateway signs webhooks with a signature field in the body, over the
+// other fields as described for SigningString, and that is checked by
+// default. When the request carries a WebhookSignatureHeader instead, the
+// body is verified with VerifyWebhookRaw and the body's own signature field,
+// if any, is ignored.
+//
+// JSON bodies are verified over the values exactly as received, falling back
+// to VerifyWebhookSignature on the decoded WebhookPayload. Form-encoded
+// bodies (application/x-www-form-urlencoded) are verified over the form
+// values exactly as delivered. ErrInvalidSignature is returned when the
+// signature does not match, ErrEmptyWebhookBody for an empty body and
+// ErrMalformedWebhook for one that cannot be decoded.
+func (c *Client) ParseWebhook(r *http.Request) (*WebhookPayload, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook body: %w", err)
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, ErrEmptyWebhookBody
+	}
+
+	var payload *WebhookPayload
+	var verifyBody func() bool
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/x-www-form-urlencoded" {
+		var params map[string]string
+		if payload, params, err = parseWebhookForm(body); err != nil {
+			return nil, err
+		}
+		verifyBody = func() bool {
+			return hmacEqual(c.calculateSignature(params), params["signature"])
+		}
+	} else {
+		payload = &WebhookPayload{}
+		if err := json.Unmarshal(body, payload); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMalformedWebhook, err)
+		}
+		verifyBody = func() bool {
+			return c.verifyRawWebhookJSON(body) || c.verifyWebhookSignature(payload)
+		}
+	}
+
+	var ok bool
+	if headerSignature := r.Header.Get(WebhookSignatureHeader); headerSignature != "" {
+		ok = c.VerifyWebhookRaw(body, headerSignature)
+	} else {
+		ok = verifyBody()
+	}
+	c.webhookVerified(payload, ok)
+	if !ok {
+		return nil, ErrInvalidSignature
+	}
+	return payload, nil
+}
+
+// VerifyWebhookRaw checks signature, the hex HMAC-SHA256 of the exact body
+// bytes keyed with the API secret, as sent in WebhookSignatureHeader. A
+// "sha256=" prefix is accepted. Because nothing is decoded or re-encoded,
+// field order and number formatting cannot affect the result.
+func (c *Client) VerifyWebhookRaw(body []byte, signature string) bool {
+	signature = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(signature), "sha256="))
+	return signature != "" && hmacEqual(signHMAC(c.credentials().apiSecret, string(body)), signature)
+}
+
+// verifyRawWebhookJSON verifies a JSON webhook over its numbers as written,
+// so "100" and "100.00" each match exactly what the gateway signed
+func (c *Client) verifyRawWebhookJSON(body []byte) bool {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var raw map[string]interface{}
+	if err := dec.Decode(&raw); err != nil {
+		return false
+	}
+	return c.VerifyWebhookSignatureFromMap(raw)
+}
+
+// amountVariants returns the renderings of a signed amount to try: the
+// fixed decimals form ("100.00") and, when different, the shortest form
+// without trailing zeros ("100")
+func amountVariants(v float64, decimals int) []string {
+	fixed := strconv.FormatFloat(v, 'f', decimals, 64)
+	short := strconv.FormatFloat(v, 'f', -1, 64)
+	if short == fixed {
+		return []string{fixed}
+	}
+	return []string{fixed, short}
+}
+
+// amountDecimals holds the decimals the gateway signs each amount field with
+var amountDecimals = map[string]int{"amount": 2, "actual_amount": 4, "amount_received": 4}
+
+// signedAmountVariants returns the renderings of a decoded amount to try:
+// a json.Number as written first, then the fixed decimals and shortest
+// forms. It returns nil for a value that is not a number.
+func signedAmountVariants(v interface{}, decimals int) []string {
+	switch val := v.(type) {
+	case float64:
+		return amountVariants(val, decimals)
+	case json.Number:
+		f, err := val.Float64()
+		if err != nil {
+			return nil
+		}
+		variants := []string{val.String()}
+		for _, variant := range amountVariants(f, decimals) {
+			if variant != val.String() {
+				variants = append(variants, variant)
+			}
+		}
+		return variants
+	}
+	return nil
+}
+
+// formatSignedValue renders a decoded webhook value the way the gateway
+// signs it: strings and json.Number verbatim, whole numbers without a
+// fraction or exponent ("1700000000", not "1.7e+09"), other numbers in their
+// shortest form, booleans as "true" or "false", and objects and arrays as
+// compact JSON with sorted keys. It reports false for values JSON cannot
+// represent.
+func formatSignedValue(v interface{}) (string, bool) {
+	switch val := v.(type) {
+	case string:
+		return val, true
+	case json.Number:
+		return val.String(), true
+	case float64:
+		if val == math.Trunc(val) && math.Abs(val) < 1<<53 {
+			return strconv.FormatInt(int64(val), 10), true
+		}
+		return strconv.FormatFloat(val, 'f', -1, 64), true
+	case int:
+		return strconv.Itoa(val), true
+	case int64:
+		return strconv.FormatInt(val, 10), true
+	case bool:
+		return strconv.FormatBool(val), true
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", false
+	}
+	return string(b), true
+}
+
+// verifyAmountVariants checks signature over params combined with every
+// choice of rendering for the amount fields
+func (c *Client) verifyAmountVariants(params map[string]string, amounts map[string][]string, signature string) bool {
+	for key, variants := range amounts {
+		rest := make(map[string][]string, len(amounts)-1)
+		for k, v := range amounts {
+			if k != key {
+				rest[k] = v
+			}
+		}
+
+		for _, variant := range variants {
+			withAmount := make(map[string]string, len(params)+1)
+			for k, v := range params {
+				withAmount[k] = v
+			}
+			withAmount[key] = variant
+			if c.verifyAmountVariants(withAmount, rest, signature) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return hmacEqual(c.calculateSignature(params), signature)
+}
+
+// WebhookHandler returns an http.HandlerFunc that parses each delivery with
+// ParseWebhook and calls fn only for verified payloads, then answers "ok".
+// Requests that are not POST get 405, empty or malformed bodies 400, and bad
+// signatures 401, so the gateway can tell rejected deliveries apart.
+func (c *Client) WebhookHandler(fn func(*WebhookPayload)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		payload, err := c.ParseWebhook(r)
+		switch {
+		case errors.Is(err, ErrInvalidSignature):
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		fn(payload)
+		w.Write([]byte("ok"))
+	}
+}
+
+// parseWebhookForm decodes a form-encoded webhook body, returning the form
+// values to verify its signature field over
+func parseWebhookForm(body []byte) (*WebhookPayload, map[string]string, error) {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrMalformedWebhook, err)
+	}
+
+	params := make(map[string]string, len(values))
+	for k := range values {
+		params[k] = values.Get(k)
+	}
+
+	payload := &WebhookPayload{
+		TradeID:            params["trade_id"],
+		OrderID:            params["order_id"],
+		Token:              params["token"],
+		ChainType:          ChainType(params["chain_type"]),
+		ChainName:          params["chain_name"],
+		BlockTransactionID: params["block_transaction_id"],
+		Signature:          params["signature"],
+		SignType:           params["sign_type"],
+		EventType:          WebhookEventType(params["event_type"]),
+	}
+
+	if payload.Amount, err = parseFormFloat(params, "amount"); err != nil {
+		return nil, nil, err
+	}
+	if payload.ActualAmount, err = parseFormFloat(params, "actual_amount"); err != nil {
+		return nil, nil, err
+	}
+	if payload.AmountReceived, err = parseFormFloat(params, "amount_received"); err != nil {
+		return nil, nil, err
+	}
+	if v := params["status"]; v != "" {
+		status, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: invalid status %q", ErrMalformedWebhook, v)
+		}
+		payload.Status = PaymentStatus(status)
+	}
+	if v := params["timestamp"]; v != "" {
+		if payload.Timestamp, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return nil, nil, fmt.Errorf("%w: invalid timestamp %q", ErrMalformedWebhook, v)
+		}
+	}
+
+	return payload, params, nil
+}
+
+func parseFormFloat(params map[string]string, key string) (float64, error) {
+	v := params[key]
+	if v == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid %s %q", ErrMalformedWebhook, key, v)
+	}
+	return f, nil
+}
+
+// VerifyWebhooks verifies a batch of stored webhook payloads, for example
+// from a log during incident review. The result has one entry per payload in
+// input order: nil when authentic, ErrInvalidSignature otherwise.
+func (c *Client) VerifyWebhooks(payloads []*WebhookPayload) []error {
+	results := make([]error, len(payloads))
+	for i, payload := range payloads {
+		switch {
+		case payload == nil:
+			results[i] = errors.New("cryptomepay: nil webhook payload")
+		case !c.VerifyWebhookSignature(payload):
+			results[i] = ErrInvalidSignature
+		}
+	}
+	return results
+}
+
+// IsStatusPing reports whether the payload is a minimal lifecycle ping that
+// carries only trade_id, status and signature, without order details.
+func (p *WebhookPayload) IsStatusPing() bool {
+	return p.OrderID == "" && p.Amount == 0 && p.ActualAmount == 0
+}
+
+// ResolveWebhookOrder returns the full order for a verified webhook payload.
+//
+// For a status ping the order is fetched with QueryPaymentByTradeID. For a
+// full payload the order data is built from the payload itself without an
+// extra request.
+func (c *Client) ResolveWebhookOrder(payload *WebhookPayload) (*OrderData, error) {
+	if !payload.IsStatusPing() {
+		return &OrderData{
+			TradeID:            payload.TradeID,
+			OrderID:            payload.OrderID,
+			Amount:             payload.Amount,
+			ActualAmount:       payload.ActualAmount,
+			AmountReceived:     payload.AmountReceived,
+			Token:              payload.Token,
+			ChainType:          payload.ChainType,
+			Status:             payload.Status,
+			BlockTransactionID: payload.BlockTransactionID,
+		}, nil
+	}
+
+	resp, err := c.QueryPaymentByTradeID(payload.TradeID)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 || resp.Data == nil {
+		return nil, NewAPIError(resp.StatusCode, resp.Message, resp.RequestID)
+	}
+	return resp.Data, nil
+}
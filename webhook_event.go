@@ -0,0 +1,107 @@
+package cryptomepay
+
+import (
+	"net/http"
+	"sync"
+)
+
+// WebhookEventType names the kind of event a webhook reports
+type WebhookEventType string
+
+// Webhook event types
+const (
+	EventPaymentPaid     WebhookEventType = "payment.paid"
+	EventOrderExpired    WebhookEventType = "order.expired"
+	EventRefundCompleted WebhookEventType = "refund.completed"
+)
+
+// WebhookEvent is a verified webhook with its event type.
+//
+// The event_type field is signed along with the rest of the payload. For
+// deliveries without it, Type is derived from the payment status: paid
+// orders are EventPaymentPaid and expired ones EventOrderExpired. Type is
+// empty for other deliveries without an event type, such as pending
+// status pings.
+type WebhookEvent struct {
+	Type    WebhookEventType
+	Payload *WebhookPayload
+}
+
+// newWebhookEvent wraps a verified payload
+func newWebhookEvent(payload *WebhookPayload) *WebhookEvent {
+	eventType := payload.EventType
+	if eventType == "" {
+		switch payload.Status {
+		case StatusPaid:
+			eventType = EventPaymentPaid
+		case StatusExpired:
+			eventType = EventOrderExpired
+		}
+	}
+	return &WebhookEvent{Type: eventType, Payload: payload}
+}
+
+// ParseWebhookEvent reads and verifies a webhook like ParseWebhook and
+// returns it as an event
+func (c *Client) ParseWebhookEvent(r *http.Request) (*WebhookEvent, error) {
+	payload, err := c.ParseWebhook(r)
+	if err != nil {
+		return nil, err
+	}
+	return newWebhookEvent(payload), nil
+}
+
+// WebhookDispatcher is an http.Handler that verifies webhooks and calls the
+// handler registered for their event type. It answers like WebhookHandler;
+// events without a handler are acknowledged and dropped, so the gateway
+// does not redeliver them. Handlers may be registered at any time.
+type WebhookDispatcher struct {
+	client *Client
+
+	mu       sync.RWMutex
+	handlers map[WebhookEventType]func(*WebhookEvent)
+}
+
+// NewWebhookDispatcher returns a dispatcher verifying webhooks with c
+func (c *Client) NewWebhookDispatcher() *WebhookDispatcher {
+	return &WebhookDispatcher{client: c, handlers: map[WebhookEventType]func(*WebhookEvent){}}
+}
+
+// On registers fn for events of eventType, replacing any earlier handler
+func (d *WebhookDispatcher) On(eventType WebhookEventType, fn func(*WebhookEvent)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers[eventType] = fn
+}
+
+// OnPaymentPaid registers fn for EventPaymentPaid
+func (d *WebhookDispatcher) OnPaymentPaid(fn func(*WebhookEvent)) {
+	d.On(EventPaymentPaid, fn)
+}
+
+// OnOrderExpired registers fn for EventOrderExpired
+func (d *WebhookDispatcher) OnOrderExpired(fn func(*WebhookEvent)) {
+	d.On(EventOrderExpired, fn)
+}
+
+// OnRefundCompleted registers fn for EventRefundCompleted
+func (d *WebhookDispatcher) OnRefundCompleted(fn func(*WebhookEvent)) {
+	d.On(EventRefundCompleted, fn)
+}
+
+// ServeHTTP verifies the webhook and dispatches it
+func (d *WebhookDispatcher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.client.WebhookHandler(d.dispatch).ServeHTTP(w, r)
+}
+
+func (d *WebhookDispatcher) dispatch(payload *WebhookPayload) {
+	event := newWebhookEvent(payload)
+
+	d.mu.RLock()
+	fn := d.handlers[event.Type]
+	d.mu.RUnlock()
+
+	if fn != nil {
+		fn(event)
+	}
+}
@@ -0,0 +1,76 @@
+package cryptomepay
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// signedWebhook returns a JSON webhook body signed by client over fields
+func signedWebhook(client *Client, fields map[string]string) string {
+	body := map[string]interface{}{"signature": client.calculateSignature(fields)}
+	for k, v := range fields {
+		body[k] = v
+	}
+	b, _ := json.Marshal(body)
+	return string(b)
+}
+
+func TestWebhookDispatcher(t *testing.T) {
+	client := NewClient("sk_test_key", "test_secret")
+
+	var got []string
+	dispatcher := client.NewWebhookDispatcher()
+	dispatcher.OnPaymentPaid(func(e *WebhookEvent) { got = append(got, "paid:"+e.Payload.TradeID) })
+	dispatcher.OnOrderExpired(func(e *WebhookEvent) { got = append(got, "expired:"+e.Payload.TradeID) })
+	dispatcher.OnRefundCompleted(func(e *WebhookEvent) { got = append(got, "refund:"+e.Payload.TradeID) })
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"explicit type", signedWebhook(client, map[string]string{"trade_id": "CP1", "event_type": "refund.completed"}), http.StatusOK},
+		{"paid status", signedWebhook(client, map[string]string{"trade_id": "CP2", "status": "2"}), http.StatusOK},
+		{"expired status", signedWebhook(client, map[string]string{"trade_id": "CP3", "status": "3"}), http.StatusOK},
+		{"unhandled type", signedWebhook(client, map[string]string{"trade_id": "CP4", "event_type": "chargeback.opened"}), http.StatusOK},
+		{"bad signature", `{"trade_id":"CP5","event_type":"payment.paid","signature":"bad"}`, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/webhook", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			dispatcher.ServeHTTP(rec, req)
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+
+	assert.Equal(t, []string{"refund:CP1", "paid:CP2", "expired:CP3"}, got)
+}
+
+func TestWebhookEventTypeIsSigned(t *testing.T) {
+	client := NewClient("sk_test_key", "test_secret")
+
+	// Changing the event type of a paid webhook breaks its signature
+	fields := map[string]string{"trade_id": "CP1", "status": "2", "event_type": "payment.paid"}
+	payload := &WebhookPayload{TradeID: "CP1", Status: StatusPaid, EventType: EventPaymentPaid, Signature: client.calculateSignature(fields)}
+	assert.True(t, client.VerifyWebhookSignature(payload))
+	payload.EventType = EventRefundCompleted
+	assert.False(t, client.VerifyWebhookSignature(payload))
+
+	req := httptest.NewRequest("POST", "/webhook", strings.NewReader(
+		strings.Replace(signedWebhook(client, fields), "payment.paid", "refund.completed", 1)))
+	_, err := client.ParseWebhookEvent(req)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	req = httptest.NewRequest("POST", "/webhook", strings.NewReader(signedWebhook(client, fields)))
+	event, err := client.ParseWebhookEvent(req)
+	assert.NoError(t, err)
+	assert.Equal(t, EventPaymentPaid, event.Type)
+	assert.Equal(t, "CP1", event.Payload.TradeID)
+}
@@ -0,0 +1,461 @@
+package cryptomepay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyWebhookSignatureStatusPing(t *testing.T) {
+	client := NewClient("sk_test_key", "test_secret")
+
+	// A ping signs only the fields it carries
+	params := map[string]string{
+		"trade_id": "CP123",
+		"status":   "2",
+	}
+
+	payload := &WebhookPayload{
+		TradeID:   "CP123",
+		Status:    StatusPaid,
+		Signature: client.calculateSignature(params),
+	}
+
+	assert.True(t, payload.IsStatusPing())
+	assert.True(t, client.VerifyWebhookSignature(payload))
+
+	payload.Status = StatusExpired
+	assert.False(t, client.VerifyWebhookSignature(payload))
+}
+
+func TestResolveWebhookOrder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "CP123", r.URL.Query().Get("trade_id"))
+
+		resp := OrderResponse{
+			StatusCode: 200,
+			Message:    "success",
+			Data: &OrderData{
+				TradeID:      "CP123",
+				OrderID:      "ORDER_001",
+				ActualAmount: 15.6250,
+				Status:       StatusPaid,
+			},
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("sk_test_key", "test_secret", WithBaseURL(server.URL))
+
+	// Status ping is resolved via the query endpoint
+	order, err := client.ResolveWebhookOrder(&WebhookPayload{TradeID: "CP123", Status: StatusPaid})
+	assert.NoError(t, err)
+	assert.Equal(t, "ORDER_001", order.OrderID)
+	assert.Equal(t, 15.6250, order.ActualAmount)
+
+	// Full payload is resolved locally
+	order, err = client.ResolveWebhookOrder(&WebhookPayload{TradeID: "CP9", OrderID: "O9", Amount: 1, ActualAmount: 0.1563, AmountReceived: 0.1, Status: StatusPaid})
+	assert.NoError(t, err)
+	assert.Equal(t, "O9", order.OrderID)
+	assert.Equal(t, 0.1563, order.ActualAmount)
+	assert.Equal(t, 0.1, order.AmountReceived)
+}
+
+func TestParseWebhookJSON(t *testing.T) {
+	client := NewClient("sk_test_key", "test_secret")
+
+	params := map[string]string{
+		"trade_id":      "CP123",
+		"order_id":      "ORDER_001",
+		"amount":        "100.00",
+		"actual_amount": "15.6250",
+		"status":        "2",
+	}
+
+	payload := WebhookPayload{
+		TradeID:      "CP123",
+		OrderID:      "ORDER_001",
+		Amount:       100,
+		ActualAmount: 15.625,
+		Status:       StatusPaid,
+		Signature:    client.calculateSignature(params),
+	}
+	body, _ := json.Marshal(payload)
+
+	req := httptest.NewRequest("POST", "/webhook", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	parsed, err := client.ParseWebhook(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "ORDER_001", parsed.OrderID)
+
+	payload.Signature = "bad"
+	body, _ = json.Marshal(payload)
+	req = httptest.NewRequest("POST", "/webhook", bytes.NewReader(body))
+	_, err = client.ParseWebhook(req)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestVerifyWebhookSignatureAmountForms(t *testing.T) {
+	client := NewClient("sk_test_key", "test_secret")
+
+	tests := []struct {
+		name         string
+		amount       string
+		actualAmount string
+	}{
+		{"fixed decimals", "100.00", "15.6250"},
+		{"trailing zeros trimmed", "100", "15.625"},
+		{"mixed", "100", "15.6250"},
+		{"raw only", "100.000", "15.62500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signature := client.calculateSignature(map[string]string{
+				"trade_id":      "CP123",
+				"amount":        tt.amount,
+				"actual_amount": tt.actualAmount,
+				"status":        "2",
+			})
+			body := `{"trade_id":"CP123","amount":` + tt.amount + `,"actual_amount":` + tt.actualAmount +
+				`,"status":2,"signature":"` + signature + `"}`
+
+			req := httptest.NewRequest("POST", "/webhook", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			_, err := client.ParseWebhook(req)
+			assert.NoError(t, err)
+
+			// Decoded paths recognise the fixed and trimmed forms
+			if tt.name != "raw only" {
+				var payload WebhookPayload
+				json.Unmarshal([]byte(body), &payload)
+				assert.True(t, client.VerifyWebhookSignature(&payload))
+
+				var raw map[string]interface{}
+				json.Unmarshal([]byte(body), &raw)
+				assert.True(t, client.VerifyWebhookSignatureFromMap(raw))
+			}
+		})
+	}
+
+	// A different amount must still fail in every form
+	signature := client.calculateSignature(map[string]string{"trade_id": "CP123", "amount": "100", "status": "2"})
+	payload := &WebhookPayload{TradeID: "CP123", Amount: 101, Status: StatusPaid, Signature: signature}
+	assert.False(t, client.VerifyWebhookSignature(payload))
+}
+
+func TestParseWebhookOverpaid(t *testing.T) {
+	client := NewClient("sk_test_key", "test_secret")
+
+	params := map[string]string{
+		"trade_id":        "CP123",
+		"amount":          "100.00",
+		"actual_amount":   "15.6250",
+		"amount_received": "16.0000",
+		"status":          "2",
+	}
+	body := `{"trade_id":"CP123","amount":100,"actual_amount":15.625,"amount_received":"16.0000","status":2,"signature":"` +
+		client.calculateSignature(params) + `"}`
+
+	payload, err := client.ParseWebhook(httptest.NewRequest("POST", "/webhook", strings.NewReader(body)))
+	assert.NoError(t, err)
+	assert.Equal(t, 16.0, payload.AmountReceived)
+	assert.True(t, payload.IsOverpaid())
+	assert.False(t, payload.IsPartiallyPaid())
+	assert.Equal(t, 0.375, payload.Excess())
+
+	// amount_received is signed
+	tampered := strings.Replace(body, `"16.0000"`, `"26.0000"`, 1)
+	_, err = client.ParseWebhook(httptest.NewRequest("POST", "/webhook", strings.NewReader(tampered)))
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestParseWebhookForm(t *testing.T) {
+	client := NewClient("sk_test_key", "test_secret")
+
+	form := url.Values{}
+	form.Set("trade_id", "CP123")
+	form.Set("order_id", "ORDER_001")
+	form.Set("amount", "100.00")
+	form.Set("actual_amount", "15.6250")
+	form.Set("chain_type", "TRC20")
+	form.Set("status", "2")
+	form.Set("timestamp", "1700000000")
+
+	params := map[string]string{}
+	for k := range form {
+		params[k] = form.Get(k)
+	}
+	form.Set("signature", client.calculateSignature(params))
+
+	req := httptest.NewRequest("POST", "/webhook", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	payload, err := client.ParseWebhook(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "CP123", payload.TradeID)
+	assert.Equal(t, 100.0, payload.Amount)
+	assert.Equal(t, 15.625, payload.ActualAmount)
+	assert.Equal(t, StatusPaid, payload.Status)
+	assert.Equal(t, int64(1700000000), payload.Timestamp)
+
+	// Tampered form value
+	form.Set("amount", "1.00")
+	req = httptest.NewRequest("POST", "/webhook", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	_, err = client.ParseWebhook(req)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestVerifyWebhooks(t *testing.T) {
+	client := NewClient("sk_test_key", "test_secret")
+
+	valid := &WebhookPayload{TradeID: "CP1", Status: StatusPaid}
+	valid.Signature = client.calculateSignature(map[string]string{"trade_id": "CP1", "status": "2"})
+	forged := &WebhookPayload{TradeID: "CP2", Status: StatusPaid, Signature: "forged"}
+
+	results := client.VerifyWebhooks([]*WebhookPayload{valid, forged, nil})
+	assert.Len(t, results, 3)
+	assert.NoError(t, results[0])
+	assert.ErrorIs(t, results[1], ErrInvalidSignature)
+	assert.Error(t, results[2])
+}
+
+func TestParseWebhookEmptyAndMalformed(t *testing.T) {
+	client := NewClient("sk_test_key", "test_secret")
+
+	req := httptest.NewRequest("POST", "/webhook", strings.NewReader(""))
+	_, err := client.ParseWebhook(req)
+	assert.ErrorIs(t, err, ErrEmptyWebhookBody)
+
+	req = httptest.NewRequest("POST", "/webhook", strings.NewReader(`{"trade_id":`))
+	_, err = client.ParseWebhook(req)
+	assert.ErrorIs(t, err, ErrMalformedWebhook)
+	assert.NotErrorIs(t, err, ErrEmptyWebhookBody)
+}
+
+func TestWebhookHandler(t *testing.T) {
+	client := NewClient("sk_test_key", "test_secret")
+
+	signature := client.calculateSignature(map[string]string{"trade_id": "CP123", "status": "2"})
+	valid := `{"trade_id":"CP123","status":2,"signature":"` + signature + `"}`
+
+	var delivered []*WebhookPayload
+	handler := client.WebhookHandler(func(p *WebhookPayload) {
+		delivered = append(delivered, p)
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		status int
+	}{
+		{"verified", "POST", valid, http.StatusOK},
+		{"bad signature", "POST", `{"trade_id":"CP123","status":2,"signature":"bad"}`, http.StatusUnauthorized},
+		{"empty body", "POST", "", http.StatusBadRequest},
+		{"malformed body", "POST", "{", http.StatusBadRequest},
+		{"wrong method", "GET", "", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler(rec, httptest.NewRequest(tt.method, "/webhook", strings.NewReader(tt.body)))
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+
+	assert.Len(t, delivered, 1)
+	assert.Equal(t, "CP123", delivered[0].TradeID)
+}
+
+func TestVerifyWebhookRaw(t *testing.T) {
+	client := NewClient("sk_test_key", "test_secret")
+	body := []byte(`{"trade_id":"CP123","amount":100.00,"status":2}`)
+	signature := signHMAC("test_secret", string(body))
+
+	assert.True(t, client.VerifyWebhookRaw(body, signature))
+	assert.True(t, client.VerifyWebhookRaw(body, "sha256="+strings.ToUpper(signature)))
+	assert.False(t, client.VerifyWebhookRaw(body, ""))
+	assert.False(t, client.VerifyWebhookRaw(body, signHMAC("other_secret", string(body))))
+
+	// Any change to the bytes, even formatting, breaks the signature
+	assert.False(t, client.VerifyWebhookRaw([]byte(`{"trade_id":"CP123","amount":100,"status":2}`), signature))
+}
+
+func TestParseWebhookSignatureHeader(t *testing.T) {
+	client := NewClient("sk_test_key", "test_secret")
+
+	parse := func(contentType, body, signature string) (*WebhookPayload, error) {
+		req := httptest.NewRequest("POST", "/webhook", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set(WebhookSignatureHeader, signature)
+		return client.ParseWebhook(req)
+	}
+
+	// The body has no signature field; the header covers the raw bytes
+	body := `{"trade_id":"CP123","amount":100.00,"status":2}`
+	payload, err := parse("application/json", body, signHMAC("test_secret", body))
+	assert.NoError(t, err)
+	assert.Equal(t, "CP123", payload.TradeID)
+	assert.Equal(t, StatusPaid, payload.Status)
+
+	form := url.Values{"trade_id": {"CP456"}, "status": {"2"}}.Encode()
+	payload, err = parse("application/x-www-form-urlencoded", form, signHMAC("test_secret", form))
+	assert.NoError(t, err)
+	assert.Equal(t, "CP456", payload.TradeID)
+
+	// A header signature that does not match is rejected even when the
+	// in-body signature would verify
+	params := map[string]string{"trade_id": "CP123", "status": "2"}
+	signed, _ := json.Marshal(map[string]interface{}{"trade_id": "CP123", "status": 2, "signature": client.calculateSignature(params)})
+	_, err = parse("application/json", string(signed), "bad")
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	payload, err = parse("application/json", string(signed), "")
+	assert.NoError(t, err)
+	assert.Equal(t, "CP123", payload.TradeID)
+}
+
+func TestVerifyWebhookSignatureFromDecodedMap(t *testing.T) {
+	client := NewClient("sk_test_key", "test_secret")
+
+	// The gateway signs fixed decimal amounts and whole numbers as integers
+	signed := map[string]string{
+		"trade_id":      "CP123",
+		"order_id":      "ORDER_001",
+		"amount":        "100.00",
+		"actual_amount": "15.6250",
+		"status":        "2",
+		"timestamp":     "1700000000",
+		"test_mode":     "false",
+		"metadata":      `{"plan":"pro","seats":3}`,
+	}
+	signature := client.calculateSignature(signed)
+
+	bodies := []string{
+		`{"trade_id":"CP123","order_id":"ORDER_001","amount":100,"actual_amount":15.625,"status":2,` +
+			`"timestamp":1700000000,"test_mode":false,"metadata":{"seats":3,"plan":"pro"},"signature":"` + signature + `"}`,
+		`{"trade_id":"CP123","order_id":"ORDER_001","amount":100.00,"actual_amount":15.6250,"status":2,` +
+			`"timestamp":1700000000,"test_mode":false,"metadata":{"plan":"pro","seats":3},"signature":"` + signature + `"}`,
+	}
+	for _, body := range bodies {
+		var decoded map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(body), &decoded))
+		assert.True(t, client.VerifyWebhookSignatureFromMap(decoded), body)
+
+		dec := json.NewDecoder(strings.NewReader(body))
+		dec.UseNumber()
+		var numbers map[string]interface{}
+		assert.NoError(t, dec.Decode(&numbers))
+		assert.True(t, client.VerifyWebhookSignatureFromMap(numbers), body)
+	}
+
+	var tampered map[string]interface{}
+	json.Unmarshal([]byte(bodies[0]), &tampered)
+	tampered["timestamp"] = float64(1700000001)
+	assert.False(t, client.VerifyWebhookSignatureFromMap(tampered))
+}
+
+func TestFormatSignedValue(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want string
+	}{
+		{"100.00", "100.00"},
+		{json.Number("1.50"), "1.50"},
+		{float64(1700000000), "1700000000"},
+		{float64(-3), "-3"},
+		{1.5, "1.5"},
+		{7, "7"},
+		{int64(8), "8"},
+		{true, "true"},
+		{false, "false"},
+		{[]interface{}{"a", float64(1)}, `["a",1]`},
+		{map[string]interface{}{"b": 1, "a": "x"}, `{"a":"x","b":1}`},
+	}
+	for _, tt := range tests {
+		got, ok := formatSignedValue(tt.in)
+		assert.True(t, ok)
+		assert.Equal(t, tt.want, got)
+	}
+
+	_, ok := formatSignedValue(func() {})
+	assert.False(t, ok)
+}
+
+func TestWithWebhookVerifiedHook(t *testing.T) {
+	var mu sync.Mutex
+	verified := map[string][]bool{}
+	client := NewClientWithOptions("sk_test_key", "test_secret",
+		WithWebhookVerifiedHook(func(payload *WebhookPayload, ok bool) {
+			mu.Lock()
+			defer mu.Unlock()
+			verified[payload.TradeID] = append(verified[payload.TradeID], ok)
+		}),
+	)
+
+	delivery := func(tradeID string, valid bool) *http.Request {
+		params := map[string]string{"trade_id": tradeID, "status": "2"}
+		payload := WebhookPayload{TradeID: tradeID, Status: StatusPaid, Signature: client.calculateSignature(params)}
+		if !valid {
+			payload.Signature = "bad"
+		}
+		body, _ := json.Marshal(payload)
+		return httptest.NewRequest("POST", "/webhook", bytes.NewReader(body))
+	}
+
+	// Each delivery is reported once, also from concurrent handlers
+	handler := client.WebhookHandler(func(*WebhookPayload) {})
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			handler.ServeHTTP(httptest.NewRecorder(), delivery(fmt.Sprintf("CP%d", i), i%2 == 0))
+		}(i)
+	}
+	wg.Wait()
+	assert.Len(t, verified, 20)
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, []bool{i%2 == 0}, verified[fmt.Sprintf("CP%d", i)])
+	}
+
+	// Form bodies, header signatures and direct checks are reported too
+	params := map[string]string{"trade_id": "CP_FORM", "status": "2"}
+	form := url.Values{"trade_id": {"CP_FORM"}, "status": {"2"}, "signature": {client.calculateSignature(params)}}
+	req := httptest.NewRequest("POST", "/webhook", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	_, err := client.ParseWebhook(req)
+	assert.NoError(t, err)
+
+	req = delivery("CP_HEADER", true)
+	req.Header.Set(WebhookSignatureHeader, "bad")
+	_, err = client.ParseWebhook(req)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	client.VerifyWebhookSignature(&WebhookPayload{TradeID: "CP_DIRECT", Signature: "bad"})
+
+	assert.Equal(t, []bool{true}, verified["CP_FORM"])
+	assert.Equal(t, []bool{false}, verified["CP_HEADER"])
+	assert.Equal(t, []bool{false}, verified["CP_DIRECT"])
+
+	// Bodies that cannot be decoded are not reported
+	_, err = client.ParseWebhook(httptest.NewRequest("POST", "/webhook", strings.NewReader("{")))
+	assert.ErrorIs(t, err, ErrMalformedWebhook)
+	assert.Len(t, verified, 23)
+}